@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	settingsDest      = "org.freedesktop.portal.Desktop"
+	settingsPath      = "/org/freedesktop/portal/desktop"
+	settingsIface     = "org.freedesktop.portal.Settings"
+	appearanceNS      = "org.freedesktop.appearance"
+	colorSchemeKey    = "color-scheme"
+	reducedMotionKey  = "reduced-motion" // 0 - no preference, 1 - reduce motion
+	gnomeInterfaceNS  = "org.gnome.desktop.interface"
+	enableAnimations  = "enable-animations" // GNOME setting that is used when the portal has no reduced-motion
+	schemeLight       = 2                   // color-scheme value of light desktop (0 - no preference, 1 - dark)
+	autoTheme         = "auto"              // theme name that selects the icons theme by desktop color scheme
+	motionReduceValue = 1                   // reduced-motion value that requests reduced motion
+)
+
+// appearanceState is the desktop appearance settings
+type appearanceState struct {
+	scheme        uint32 // color scheme
+	reducedMotion bool   // desktop requests reduced motion (animations are disabled)
+}
+
+// appearance reads the desktop appearance settings via the XDG desktop portal Settings interface and reports
+// their changes
+type appearance struct {
+	ctx     context.Context
+	cancel  func()
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+	changes chan appearanceState // new appearance settings
+	done    chan struct{}
+	state   appearanceState
+}
+
+// newAppearance connects to the portal Settings interface and starts watching the appearance settings changes.
+// It returns the current settings. The color scheme is required, the reduced motion is false when it is not
+// provided by portal. Use close to release the resources.
+func newAppearance() (*appearance, appearanceState, error) {
+	var state appearanceState
+	conn, err := dbus.ConnectSessionBus() // private connection as it is closed by close
+	if err != nil {
+		return nil, state, err
+	}
+	obj := conn.Object(settingsDest, settingsPath)
+	v, err := readSetting(obj, appearanceNS, colorSchemeKey)
+	if err == nil {
+		state, err = applySetting(state, appearanceNS, colorSchemeKey, v)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, state, fmt.Errorf("color scheme is not available: %w", err)
+	}
+	if v, err := readSetting(obj, appearanceNS, reducedMotionKey); err == nil {
+		state, _ = applySetting(state, appearanceNS, reducedMotionKey, v)
+	} else if v, err := readSetting(obj, gnomeInterfaceNS, enableAnimations); err == nil {
+		state, _ = applySetting(state, gnomeInterfaceNS, enableAnimations, v)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &appearance{
+		ctx:     ctx,
+		cancel:  cancel,
+		conn:    conn,
+		signals: make(chan *dbus.Signal, 10),
+		changes: make(chan appearanceState, 1),
+		done:    make(chan struct{}),
+		state:   state,
+	}
+	for _, ns := range []string{appearanceNS, gnomeInterfaceNS} {
+		if err := conn.AddMatchSignalContext(ctx, dbus.WithMatchObjectPath(settingsPath),
+			dbus.WithMatchInterface(settingsIface), dbus.WithMatchMember("SettingChanged"),
+			dbus.WithMatchArg(0, ns)); err != nil {
+			cancel()
+			conn.Close()
+			return nil, state, err
+		}
+	}
+	conn.Signal(a.signals)
+	go a.loop()
+	return a, state, nil
+}
+
+// readSetting reads the setting by ReadOne method or by deprecated Read method of older portals
+func readSetting(obj dbus.BusObject, ns, key string) (dbus.Variant, error) {
+	var v dbus.Variant
+	err := obj.Call(settingsIface+".ReadOne", 0, ns, key).Store(&v)
+	if err != nil {
+		err = obj.Call(settingsIface+".Read", 0, ns, key).Store(&v)
+	}
+	return v, err
+}
+
+// unwrap returns the setting value. The deprecated Read method returns the value wrapped into one more variant.
+func unwrap(v dbus.Variant) any {
+	if inner, ok := v.Value().(dbus.Variant); ok {
+		return unwrap(inner)
+	}
+	return v.Value()
+}
+
+// applySetting returns the state with the setting value applied. It returns the unchanged state and error for
+// the setting that doesn't affect the state or has wrong value type.
+func applySetting(state appearanceState, ns, key string, v dbus.Variant) (appearanceState, error) {
+	next, ok := state, false
+	switch val := unwrap(v); {
+	case ns == appearanceNS && key == colorSchemeKey:
+		next.scheme, ok = val.(uint32)
+	case ns == appearanceNS && key == reducedMotionKey:
+		var motion uint32
+		motion, ok = val.(uint32)
+		next.reducedMotion = motion == motionReduceValue
+	case ns == gnomeInterfaceNS && key == enableAnimations:
+		var enabled bool
+		enabled, ok = val.(bool)
+		next.reducedMotion = !enabled
+	default:
+		return state, fmt.Errorf("unsupported setting: %s %s", ns, key)
+	}
+	if !ok {
+		return state, fmt.Errorf("wrong value of %s %s: %v", ns, key, v)
+	}
+	return next, nil
+}
+
+// loop reports the appearance settings changes
+func (a *appearance) loop() {
+	defer close(a.done)
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case s := <-a.signals:
+			state, ok := settingChanged(a.state, s)
+			if !ok || state == a.state {
+				continue
+			}
+			a.state = state
+			select {
+			case <-a.changes: // drop the previous unread change
+			default:
+			}
+			a.changes <- state
+		}
+	}
+}
+
+// settingChanged returns the state with the setting from SettingChanged signal applied
+func settingChanged(state appearanceState, s *dbus.Signal) (appearanceState, bool) {
+	if s.Name != settingsIface+".SettingChanged" || len(s.Body) < 3 {
+		return state, false
+	}
+	ns, _ := s.Body[0].(string)
+	key, _ := s.Body[1].(string)
+	v, ok := s.Body[2].(dbus.Variant)
+	if !ok {
+		return state, false
+	}
+	state, err := applySetting(state, ns, key, v)
+	return state, err == nil
+}
+
+// close stops watching the appearance settings and closes D-BUS connection
+func (a *appearance) close() {
+	a.cancel()
+	<-a.done
+	a.conn.RemoveSignal(a.signals)
+	a.conn.Close()
+}
+
+// schemeTheme returns the icons theme for the desktop color scheme: light icons theme for light desktop and dark
+// icons theme for dark desktop or when there is no preference (the default theme)
+func schemeTheme(scheme uint32) string {
+	if scheme == schemeLight {
+		return "light"
+	}
+	return "dark"
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySetting(t *testing.T) {
+	state, err := applySetting(appearanceState{}, appearanceNS, colorSchemeKey, dbus.MakeVariant(uint32(1)))
+	require.NoError(t, err)
+	require.Equal(t, appearanceState{scheme: 1}, state)
+	// value of deprecated Read method is wrapped into one more variant
+	state, err = applySetting(state, appearanceNS, reducedMotionKey, dbus.MakeVariant(dbus.MakeVariant(uint32(1))))
+	require.NoError(t, err)
+	require.Equal(t, appearanceState{scheme: 1, reducedMotion: true}, state)
+	state, err = applySetting(state, gnomeInterfaceNS, enableAnimations, dbus.MakeVariant(true))
+	require.NoError(t, err)
+	require.Equal(t, appearanceState{scheme: 1}, state)
+	_, err = applySetting(state, appearanceNS, colorSchemeKey, dbus.MakeVariant("dark"))
+	require.Error(t, err)
+	_, err = applySetting(state, appearanceNS, "accent-color", dbus.MakeVariant(uint32(2)))
+	require.Error(t, err)
+	unchanged, err := applySetting(state, gnomeInterfaceNS, enableAnimations, dbus.MakeVariant(uint32(0)))
+	require.Error(t, err)
+	require.Equal(t, state, unchanged)
+}
+
+func TestSettingChanged(t *testing.T) {
+	signal := func(name, ns, key string, v any) *dbus.Signal {
+		return &dbus.Signal{Name: name, Body: []any{ns, key, dbus.MakeVariant(v)}}
+	}
+	state, ok := settingChanged(appearanceState{}, signal(settingsIface+".SettingChanged", appearanceNS, colorSchemeKey, uint32(2)))
+	require.True(t, ok)
+	require.Equal(t, appearanceState{scheme: 2}, state)
+	state, ok = settingChanged(state, signal(settingsIface+".SettingChanged", gnomeInterfaceNS, enableAnimations, false))
+	require.True(t, ok)
+	require.Equal(t, appearanceState{scheme: 2, reducedMotion: true}, state)
+	_, ok = settingChanged(state, signal(settingsIface+".SettingChanged", gnomeInterfaceNS, "font-name", "Sans"))
+	require.False(t, ok)
+	_, ok = settingChanged(state, signal("org.freedesktop.DBus.NameOwnerChanged", appearanceNS, colorSchemeKey, uint32(2)))
+	require.False(t, ok)
+	_, ok = settingChanged(state, &dbus.Signal{Name: settingsIface + ".SettingChanged"})
+	require.False(t, ok)
+}
+
+func TestSchemeTheme(t *testing.T) {
+	require.Equal(t, "dark", schemeTheme(0))
+	require.Equal(t, "dark", schemeTheme(1))
+	require.Equal(t, "light", schemeTheme(2))
+}
@@ -39,86 +39,165 @@ func init() {
 }
 
 var messageKeyToIndex = map[string]int{
-	"About":                             18,
-	"Daemon started":                    28,
-	"Daemon stopped":                    27,
-	"Donations":                         19,
-	"Free: %s Trash: %s":                26,
-	"Help":                              17,
-	"Last synchronized":                 6,
-	"Light theme":                       13,
-	"Notification service unavailable!": 21,
-	"Notifications":                     14,
-	"Open Yandex.Disk folder":           10,
-	"Open Yandex.Disk in browser":       11,
-	"Quit":                              20,
-	"Settings":                          12,
-	"Show daemon output":                9,
-	"Start daemon":                      7,
-	"Start on start":                    15,
-	"Status: %s":                        24,
-	"Stop daemon":                       8,
-	"Stop on exit":                      16,
-	"Synchronization finished":          30,
-	"Synchronization started":           29,
-	"Used: %s/%s":                       25,
-	"Yandex.Disk daemon output":         22,
-	"Yandex.Disk indicator":             0,
-	"busy":                              3,
-	"idle":                              1,
-	"index":                             2,
-	"none":                              4,
-	"paused":                            5,
-	"yd-go is the panel indicator for Yandex.Disk daemon.\n\n\tVersion: %s\n\nCopyleft 2017-%s Sly_tom_cat (slytomcat@mail.ru)\n\n\tLicense: GPL v.3\n\n": 23,
+	"%d new files synchronized in the last minute": 43,
+	"%s: %d times in the last minute":              44,
+	"About":                                        17,
+	"Automatic":                                    50,
+	"Copy daemon output":                           60,
+	"Copy path":                                    57,
+	"Copy public link":                             58,
+	"Daemon output copied to clipboard":            61,
+	"Daemon started":                               27,
+	"Daemon stopped":                               26,
+	"Dark":                                         48,
+	"Default":                                      31,
+	"Disk space warnings":                          38,
+	"Disk usage on icon":                           51,
+	"Donations":                                    18,
+	"Enabled":                                      36,
+	"Error: %s":                                    53,
+	"Errors":                                       37,
+	"Exclude folder":                               59,
+	"Free: %s Trash: %s":                           25,
+	"Help":                                         16,
+	"Last synchronized":                            6,
+	"Last synchronized: %s":                        54,
+	"Light":                                        49,
+	"Low disk space: used %s of %s":                41,
+	"New files":                                    39,
+	"New files synchronized:":                      40,
+	"Notification service unavailable!":            20,
+	"Notifications":                                13,
+	"Open":                                         55,
+	"Open Yandex.Disk folder":                      10,
+	"Open Yandex.Disk in browser":                  11,
+	"Open file":                                    34,
+	"Open folder":                                  33,
+	"Path copied to clipboard":                     62,
+	"Profile":                                      30,
+	"Public link copied to clipboard":              63,
+	"Quit":                                         19,
+	"Settings":                                     12,
+	"Show daemon output":                           9,
+	"Show in folder":                               56,
+	"Show output":                                  32,
+	"Start daemon":                                 7,
+	"Start on start":                               14,
+	"Status: %s":                                   23,
+	"Stop daemon":                                  8,
+	"Stop on exit":                                 15,
+	"Synchronization error:":                       35,
+	"Synchronization finished":                     29,
+	"Synchronization started":                      28,
+	"Synchronized %d times in the last minute, %d files": 42,
+	"Theme":                     47,
+	"Time left: %s":             52,
+	"Used: %s/%s":               24,
+	"Yandex.Disk daemon output": 21,
+	"Yandex.Disk indicator":     0,
+	"busy":                      3,
+	"error":                     46,
+	"idle":                      1,
+	"index":                     2,
+	"no internet access":        45,
+	"none":                      4,
+	"paused":                    5,
+	"yd-go is the panel indicator for Yandex.Disk daemon.\n\n\tVersion: %s\n\nCopyleft 2017-%s Sly_tom_cat (slytomcat@mail.ru)\n\n\tLicense: GPL v.3\n\n": 22,
 }
 
-var en_USIndex = []uint32{ // 32 elements
+var en_USIndex = []uint32{ // 65 elements
+	// Entry 0 - 1F
 	0x00000000, 0x00000016, 0x0000001b, 0x00000021,
 	0x00000026, 0x0000002b, 0x00000032, 0x00000044,
 	0x00000051, 0x0000005d, 0x00000070, 0x00000088,
-	0x000000a4, 0x000000ad, 0x000000b9, 0x000000c7,
-	0x000000d6, 0x000000e3, 0x000000e8, 0x000000ee,
-	0x000000f8, 0x000000fd, 0x0000011f, 0x00000139,
-	0x000001ce, 0x000001dc, 0x000001ee, 0x00000207,
-	0x00000216, 0x00000225, 0x0000023d, 0x00000256,
-} // Size: 152 bytes
+	0x000000a4, 0x000000ad, 0x000000bb, 0x000000ca,
+	0x000000d7, 0x000000dc, 0x000000e2, 0x000000ec,
+	0x000000f1, 0x00000113, 0x0000012d, 0x000001c2,
+	0x000001d0, 0x000001e2, 0x000001fb, 0x0000020a,
+	0x00000219, 0x00000231, 0x0000024a, 0x00000252,
+	// Entry 20 - 3F
+	0x0000025a, 0x00000266, 0x00000272, 0x0000027c,
+	0x00000293, 0x0000029b, 0x000002a2, 0x000002b6,
+	0x000002c0, 0x000002d8, 0x000002fc, 0x00000335,
+	0x00000365, 0x0000038b, 0x0000039e, 0x000003a4,
+	0x000003aa, 0x000003af, 0x000003b5, 0x000003bf,
+	0x000003d2, 0x000003e3, 0x000003f0, 0x00000409,
+	0x0000040e, 0x0000041d, 0x00000427, 0x00000438,
+	0x00000447, 0x0000045a, 0x0000047c, 0x00000495,
+	// Entry 40 - 5F
+	0x000004b5,
+} // Size: 284 bytes
 
-const en_USData string = "" + // Size: 598 bytes
+const en_USData string = "" + // Size: 1205 bytes
 	"\x02Yandex.Disk indicator\x02idle\x02index\x02busy\x02none\x02paused\x02" +
 	"Last synchronized\x02Start daemon\x02Stop daemon\x02Show daemon output" +
 	"\x02Open Yandex.Disk folder\x02Open Yandex.Disk in browser\x02Settings" +
-	"\x02Light theme\x02Notifications\x02Start on start\x02Stop on exit\x02He" +
-	"lp\x02About\x02Donations\x02Quit\x02Notification service unavailable!" +
-	"\x02Yandex.Disk daemon output\x04\x00\x02\x0a\x0a\x8e\x01\x02yd-go is th" +
-	"e panel indicator for Yandex.Disk daemon.\x0a\x0a\x09Version: %[1]s\x0a" +
-	"\x0aCopyleft 2017-%[2]s Sly_tom_cat (slytomcat@mail.ru)\x0a\x0a\x09Licen" +
-	"se: GPL v.3\x02Status: %[1]s\x02Used: %[1]s/%[2]s\x02Free: %[1]s Trash: " +
-	"%[2]s\x02Daemon stopped\x02Daemon started\x02Synchronization started\x02" +
-	"Synchronization finished"
+	"\x02Notifications\x02Start on start\x02Stop on exit\x02Help\x02About\x02" +
+	"Donations\x02Quit\x02Notification service unavailable!\x02Yandex.Disk da" +
+	"emon output\x04\x00\x02\x0a\x0a\x8e\x01\x02yd-go is the panel indicator " +
+	"for Yandex.Disk daemon.\x0a\x0a\x09Version: %[1]s\x0a\x0aCopyleft 2017-%" +
+	"[2]s Sly_tom_cat (slytomcat@mail.ru)\x0a\x0a\x09License: GPL v.3\x02Stat" +
+	"us: %[1]s\x02Used: %[1]s/%[2]s\x02Free: %[1]s Trash: %[2]s\x02Daemon sto" +
+	"pped\x02Daemon started\x02Synchronization started\x02Synchronization fin" +
+	"ished\x02Profile\x02Default\x02Show output\x02Open folder\x02Open file" +
+	"\x02Synchronization error:\x02Enabled\x02Errors\x02Disk space warnings" +
+	"\x02New files\x02New files synchronized:\x02Low disk space: used %[1]s o" +
+	"f %[2]s\x02Synchronized %[1]d times in the last minute, %[2]d files\x02%" +
+	"[1]d new files synchronized in the last minute\x02%[1]s: %[2]d times in " +
+	"the last minute\x02no internet access\x02error\x02Theme\x02Dark\x02Light" +
+	"\x02Automatic\x02Disk usage on icon\x02Time left: %[1]s\x02Error: %[1]s" +
+	"\x02Last synchronized: %[1]s\x02Open\x02Show in folder\x02Copy path\x02C" +
+	"opy public link\x02Exclude folder\x02Copy daemon output\x02Daemon output" +
+	" copied to clipboard\x02Path copied to clipboard\x02Public link copied t" +
+	"o clipboard"
 
-var ruIndex = []uint32{ // 32 elements
+var ruIndex = []uint32{ // 65 elements
+	// Entry 0 - 1F
 	0x00000000, 0x0000001f, 0x00000030, 0x00000045,
 	0x00000060, 0x00000075, 0x00000080, 0x000000b8,
 	0x000000da, 0x000000fe, 0x00000129, 0x00000153,
-	0x00000182, 0x00000195, 0x000001ad, 0x000001c4,
-	0x000001e9, 0x00000212, 0x0000021f, 0x00000239,
-	0x00000254, 0x0000025f, 0x00000299, 0x000002bf,
-	0x0000037d, 0x00000391, 0x000003b7, 0x000003e5,
-	0x0000040b, 0x0000042b, 0x00000453, 0x00000481,
-} // Size: 152 bytes
+	0x00000182, 0x00000195, 0x000001ac, 0x000001d1,
+	0x000001fa, 0x00000207, 0x00000221, 0x0000023c,
+	0x00000247, 0x00000281, 0x000002a7, 0x00000365,
+	0x00000379, 0x0000039f, 0x000003cd, 0x000003f3,
+	0x00000413, 0x0000043b, 0x00000469, 0x00000478,
+	// Entry 20 - 3F
+	0x00000490, 0x000004ac, 0x000004c6, 0x000004de,
+	0x00000507, 0x00000518, 0x00000525, 0x00000560,
+	0x00000576, 0x000005ae, 0x000005fd, 0x00000665,
+	0x000006ca, 0x00000703, 0x0000072f, 0x0000073c,
+	0x00000745, 0x00000752, 0x00000761, 0x0000077c,
+	0x000007b4, 0x000007cc, 0x000007e0, 0x0000081f,
+	0x0000082e, 0x0000084d, 0x0000086b, 0x000008a0,
+	0x000008be, 0x000008eb, 0x00000933, 0x0000096c,
+	// Entry 40 - 5F
+	0x000009be,
+} // Size: 284 bytes
 
-const ruData string = "" + // Size: 1153 bytes
+const ruData string = "" + // Size: 2494 bytes
 	"\x02Индикатор Yandex.Disk\x02ожидание\x02индексация\x02синхронизация\x02" +
 	"остановлен\x02пауза\x02Последние синхронизированные\x02Запустить утилит" +
 	"у\x02Остановить утилиту\x02Показать вывод утилиты\x02Открыть каталог Ya" +
-	"ndex.Disk\x02Открыть Yandex.Disk в браузере\x02Настройки\x02Светлая тема" +
-	"\x02Уведомления\x02Запускать на старте\x02Остановить при выходе\x02Помощ" +
-	"ь\x02Об индикаторе\x02Пожертвования\x02Выход\x02Сервис уведомлений недо" +
-	"ступен!\x02Вывод утилиты Yandex.Disk\x04\x00\x02\x0a\x0a\xb7\x01\x02yd-" +
-	"go это индикатор панели для утилиты Yandex.Disk.\x0a\x0a\x09Версия: %[1]" +
-	"s\x0a\x0aCopyleft 2017-%[2]s Sly_tom_cat (slytomcat@mail.ru)\x0a\x0a\x09" +
-	"Лицензия: GPL v.3\x02Статус: %[1]s\x02Использовано: %[1]s/%[2]s\x02Своб" +
-	"одно: %[1]s Корзина: %[2]s\x02Утилита остановлена\x02Утилита запущена" +
-	"\x02Синхронизация начата\x02Синхронизация закончена"
+	"ndex.Disk\x02Открыть Yandex.Disk в браузере\x02Настройки\x02Уведомления" +
+	"\x02Запускать на старте\x02Остановить при выходе\x02Помощь\x02Об индикат" +
+	"оре\x02Пожертвования\x02Выход\x02Сервис уведомлений недоступен!\x02Выво" +
+	"д утилиты Yandex.Disk\x04\x00\x02\x0a\x0a\xb7\x01\x02yd-go это индикато" +
+	"р панели для утилиты Yandex.Disk.\x0a\x0a\x09Версия: %[1]s\x0a\x0aCopyl" +
+	"eft 2017-%[2]s Sly_tom_cat (slytomcat@mail.ru)\x0a\x0a\x09Лицензия: GPL " +
+	"v.3\x02Статус: %[1]s\x02Использовано: %[1]s/%[2]s\x02Свободно: %[1]s Кор" +
+	"зина: %[2]s\x02Утилита остановлена\x02Утилита запущена\x02Синхронизация" +
+	" начата\x02Синхронизация закончена\x02Профиль\x02По умолчанию\x02Показат" +
+	"ь вывод\x02Открыть папку\x02Открыть файл\x02Ошибка синхронизации:\x02Вк" +
+	"лючены\x02Ошибки\x02Предупреждения о месте на диске\x02Новые файлы\x02С" +
+	"инхронизированы новые файлы:\x02Мало места на диске: использовано %[1]s" +
+	" из %[2]s\x02Синхронизировано %[1]d раз за последнюю минуту, файлов: %[2" +
+	"]d\x02Новых файлов синхронизировано за последнюю минуту: %[1]d\x02%[1]s:" +
+	" %[2]d раз за последнюю минуту\x02нет доступа к интернету\x02ошибка\x02Т" +
+	"ема\x02Тёмная\x02Светлая\x02Автоматически\x02Заполненность диска на зна" +
+	"чке\x02Осталось: %[1]s\x02Ошибка: %[1]s\x02Последний синхронизированный" +
+	": %[1]s\x02Открыть\x02Показать в папке\x02Копировать путь\x02Копировать " +
+	"публичную ссылку\x02Исключить папку\x02Копировать вывод демона\x02Вывод" +
+	" демона скопирован в буфер обмена\x02Путь скопирован в буфер обмена\x02П" +
+	"убличная ссылка скопирована в буфер обмена"
 
-	// Total table size 2055 bytes (2KiB); checksum: E6D66B0E
+	// Total table size 4267 bytes (4KiB); checksum: 93BC0671
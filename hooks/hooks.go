@@ -0,0 +1,186 @@
+// Package hooks executes the user configured commands on the indicator events (daemon started/stopped,
+// synchronization started/finished, errors and new synchronized items).
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Event kinds
+const (
+	DaemonStarted = "daemon_started"
+	DaemonStopped = "daemon_stopped"
+	SyncStarted   = "sync_started"
+	SyncFinished  = "sync_finished"
+	Error         = "error"
+	NewItems      = "new_items"
+	AnyEvent      = "*"
+)
+
+const (
+	defaultTimeout = 60 // default hook execution timeout in seconds
+	defaultLimit   = 2  // default limit of concurrently executed hooks
+)
+
+// Hook is the user command that is executed on the indicator event
+type Hook struct {
+	Event   string // event kind (see the event kinds constants) or "*" for any event
+	Command string // command line that is executed via `sh -c`
+	Timeout int    `json:",omitempty"` // execution timeout in seconds, 0 means the default timeout (60s)
+}
+
+// Validate checks the hook fields and returns error in case of wrong values
+func (h Hook) Validate() error {
+	switch h.Event {
+	case DaemonStarted, DaemonStopped, SyncStarted, SyncFinished, Error, NewItems, AnyEvent:
+	default:
+		return fmt.Errorf("wrong hook event: '%s'", h.Event)
+	}
+	if strings.TrimSpace(h.Command) == "" {
+		return fmt.Errorf("empty hook command for event '%s'", h.Event)
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("negative hook timeout for event '%s'", h.Event)
+	}
+	return nil
+}
+
+// Event holds the event values that are passed to the hook command via environment variables
+type Event struct {
+	Kind    string   // YDGO_EVENT: event kind
+	Prev    string   // YDGO_PREV_STATUS: previous daemon status
+	Status  string   // YDGO_STATUS: new daemon status
+	Err     string   // YDGO_ERROR: error message
+	ErrPath string   // YDGO_ERROR_PATH: error path
+	SyncDir string   // YDGO_SYNC_DIR: synchronized directory
+	Items   []string // YDGO_ITEMS: new last synchronized items (new line separated)
+}
+
+// env returns the environment variables for hook command
+func (e Event) env() []string {
+	return []string{
+		"YDGO_EVENT=" + e.Kind,
+		"YDGO_PREV_STATUS=" + e.Prev,
+		"YDGO_STATUS=" + e.Status,
+		"YDGO_ERROR=" + e.Err,
+		"YDGO_ERROR_PATH=" + e.ErrPath,
+		"YDGO_SYNC_DIR=" + e.SyncDir,
+		"YDGO_ITEMS=" + strings.Join(e.Items, "\n"),
+	}
+}
+
+// Runner executes the hooks on events. The number of concurrently executed hooks is limited and the same hook
+// is never executed twice at the same time: the event is skipped when the hook is still running.
+type Runner struct {
+	hooks   []Hook             // configured hooks
+	sem     chan struct{}      // semaphore for limitation of concurrent executions
+	lock    sync.Mutex         // running map protection lock
+	running map[int]bool       // indexes of running hooks
+	wg      sync.WaitGroup     // running hooks wait group
+	ctx     context.Context    // context for cancellation of waiting hooks on Close
+	cancel  context.CancelFunc // cancel func for ctx
+	log     *slog.Logger       // logger for hooks output and errors
+}
+
+// NewRunner creates the hooks runner. The limit sets the maximum number of concurrently executed hooks,
+// use 0 for the default limit. Use Close to wait for running hooks on exit.
+func NewRunner(hooks []Hook, limit int, log *slog.Logger) *Runner {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		hooks:   hooks,
+		sem:     make(chan struct{}, limit),
+		running: make(map[int]bool, len(hooks)),
+		ctx:     ctx,
+		cancel:  cancel,
+		log:     log,
+	}
+}
+
+// Run starts the hooks that are configured for the event kind. It doesn't wait for hooks completion.
+func (r *Runner) Run(e Event) {
+	for n, h := range r.hooks {
+		if h.Event != e.Kind && h.Event != AnyEvent {
+			continue
+		}
+		r.lock.Lock()
+		if r.running[n] {
+			r.lock.Unlock()
+			r.log.Warn("hook", "event", e.Kind, "hook", n, "status", "skipped", "reason", "still running")
+			continue
+		}
+		r.running[n] = true
+		r.lock.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer func() {
+				r.lock.Lock()
+				delete(r.running, n)
+				r.lock.Unlock()
+				r.wg.Done()
+			}()
+			select {
+			case r.sem <- struct{}{}: // take the free slot first even when Close is already called
+			default:
+				select {
+				case r.sem <- struct{}{}:
+				case <-r.ctx.Done():
+					return
+				}
+			}
+			defer func() { <-r.sem }()
+			r.exec(n, h, e)
+		}()
+	}
+}
+
+// exec executes the hook command and logs its output
+func (r *Runner) exec(n int, h Hook, e Event) {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(), e.env()...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} // own process group to kill all the hook processes
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	r.log.Debug("hook", "event", e.Kind, "hook", n, "status", "started")
+	err := cmd.Run()
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		r.log.Info("hook_output", "event", e.Kind, "hook", n, "line", scanner.Text())
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		r.log.Warn("hook", "event", e.Kind, "hook", n, "status", "timeout", "timeout", timeout)
+	case err != nil:
+		r.log.Warn("hook", "event", e.Kind, "hook", n, "status", "failed", "error", err)
+	default:
+		r.log.Debug("hook", "event", e.Kind, "hook", n, "status", "finished")
+	}
+}
+
+// Close cancels the hooks that are waiting for execution and waits for completion of the running ones.
+// The running hooks are not interrupted, but they are still limited by their timeouts.
+func (r *Runner) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
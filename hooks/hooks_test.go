@@ -0,0 +1,114 @@
+package hooks
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// logBuffer is the concurrency safe buffer for logger output
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newLogger() (*slog.Logger, *logBuffer) {
+	b := &logBuffer{}
+	return slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})), b
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Hook{Event: SyncFinished, Command: "true"}.Validate())
+	require.NoError(t, Hook{Event: AnyEvent, Command: "true", Timeout: 5}.Validate())
+	require.EqualError(t, Hook{Event: "wrong", Command: "true"}.Validate(), "wrong hook event: 'wrong'")
+	require.EqualError(t, Hook{Event: Error, Command: " "}.Validate(), "empty hook command for event 'error'")
+	require.EqualError(t, Hook{Event: Error, Command: "true", Timeout: -1}.Validate(), "negative hook timeout for event 'error'")
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("environment", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		log, _ := newLogger()
+		r := NewRunner([]Hook{
+			{Event: SyncFinished, Command: `echo "$YDGO_EVENT|$YDGO_PREV_STATUS|$YDGO_STATUS|$YDGO_ERROR|$YDGO_ERROR_PATH|$YDGO_SYNC_DIR|$YDGO_ITEMS" > ` + out},
+		}, 0, log)
+		r.Run(Event{Kind: SyncStarted}) // not configured event
+		r.Run(Event{Kind: SyncFinished, Prev: "busy", Status: "idle", Err: "err", ErrPath: "path", SyncDir: "/dir", Items: []string{"a", "b"}})
+		r.Close()
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "sync_finished|busy|idle|err|path|/dir|a\nb\n", string(data))
+	})
+	t.Run("output and errors", func(t *testing.T) {
+		log, buf := newLogger()
+		r := NewRunner([]Hook{
+			{Event: AnyEvent, Command: "echo hook_line; exit 3"},
+		}, 1, log)
+		r.Run(Event{Kind: Error})
+		r.Close()
+		require.Contains(t, buf.String(), "line=hook_line")
+		require.Contains(t, buf.String(), "status=failed")
+	})
+	t.Run("timeout", func(t *testing.T) {
+		log, buf := newLogger()
+		r := NewRunner([]Hook{{Event: Error, Command: "sleep 10", Timeout: 1}}, 0, log)
+		start := time.Now()
+		r.Run(Event{Kind: Error})
+		r.Close()
+		require.Contains(t, buf.String(), "status=timeout")
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+	t.Run("skip running", func(t *testing.T) {
+		log, buf := newLogger()
+		r := NewRunner([]Hook{{Event: SyncStarted, Command: "sleep 0.2"}}, 0, log)
+		r.Run(Event{Kind: SyncStarted})
+		r.Run(Event{Kind: SyncStarted})
+		r.Close()
+		require.Contains(t, buf.String(), "status=skipped")
+	})
+	t.Run("limit", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		log, _ := newLogger()
+		cmd := "echo start >> " + out + "; sleep 0.1; echo end >> " + out
+		r := NewRunner([]Hook{{Event: SyncStarted, Command: cmd}, {Event: SyncStarted, Command: cmd}}, 1, log)
+		r.Run(Event{Kind: SyncStarted})
+		require.Eventually(t, func() bool {
+			data, _ := os.ReadFile(out)
+			return len(data) == 20
+		}, time.Second, 10*time.Millisecond)
+		r.Close()
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "start\nend\nstart\nend\n", string(data)) // hooks were executed one by one
+	})
+	t.Run("close", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		log, _ := newLogger()
+		cmd := "sleep 0.1; echo done >> " + out
+		r := NewRunner([]Hook{{Event: SyncStarted, Command: cmd}, {Event: SyncStarted, Command: cmd}}, 1, log)
+		r.Run(Event{Kind: SyncStarted})
+		require.Eventually(t, func() bool { return len(r.sem) == 1 }, time.Second, time.Millisecond)
+		r.Close() // waits for the running hook and cancels the waiting one
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "done\n", string(data))
+	})
+}
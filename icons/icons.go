@@ -2,117 +2,240 @@ package icons
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 )
 
-const busyIconsCnt = 5
-
-var interval = time.Millisecond * 333
+// defaultInterval is the default interval between busy animation frames
+const defaultInterval = time.Millisecond * 333
 
 // iconSet is set of one theme icons
 type iconsSet struct {
-	busyIcons [busyIconsCnt][]byte // busy icons set for icon animation for index and busy statuses
-	idleIcon  []byte               // idle icon data
-	pauseIcon []byte               // pause icon data
-	errorIcon []byte               // error icon data
+	busyIcons   [][]byte // busy icons set for icon animation for index and busy statuses
+	idleIcon    []byte   // idle icon data
+	pauseIcon   []byte   // pause icon data
+	errorIcon   []byte   // error icon data
+	offlineIcon []byte   // offline icon data
+	authIcon    []byte   // authorization problem icon data
+	unknownIcon []byte   // unknown status icon data
 }
 
 var (
 	lightSet = &iconsSet{
-		busyIcons: [busyIconsCnt][]byte{lightBusy1, lightBusy2, lightBusy3, lightBusy4, lightBusy5},
-		idleIcon:  lightIdle,
-		pauseIcon: lightPause,
-		errorIcon: lightError,
+		busyIcons:   [][]byte{lightBusy1, lightBusy2, lightBusy3, lightBusy4, lightBusy5},
+		idleIcon:    lightIdle,
+		pauseIcon:   lightPause,
+		errorIcon:   lightError,
+		offlineIcon: lightOffline,
+		authIcon:    lightAuth,
+		unknownIcon: lightUnknown,
 	}
 	darkSet = &iconsSet{
-		busyIcons: [busyIconsCnt][]byte{darkBusy1, darkBusy2, darkBusy3, darkBusy4, darkBusy5},
-		idleIcon:  darkIdle,
-		pauseIcon: darkPause,
-		errorIcon: darkError,
+		busyIcons:   [][]byte{darkBusy1, darkBusy2, darkBusy3, darkBusy4, darkBusy5},
+		idleIcon:    darkIdle,
+		pauseIcon:   darkPause,
+		errorIcon:   darkError,
+		offlineIcon: darkOffline,
+		authIcon:    darkAuth,
+		unknownIcon: darkUnknown,
 	}
 )
 
 // Icon is the icon helper
 type Icon struct {
-	*iconsSet                    // current theme icons set
-	LogoIcon        []byte       // bytes of logo icon
-	lock            sync.Mutex   // data protection lock
-	currentStatus   string       // current icon status
-	currentBusyIcon int          // current icon number for busy animation
-	setFunc         func([]byte) // function to set icon
-	ticker          *time.Ticker // ticker for icon animation
-	stopper         func()       // helper stop function
+	*iconsSet                             // current theme icons set
+	themes          map[string]*iconsSet  // loaded user themes
+	LogoIcon        []byte                // bytes of logo icon
+	lock            sync.Mutex            // data protection lock
+	currentStatus   string                // current icon status
+	currentBusyIcon int                   // current icon number for busy animation
+	progress        int                   // synchronization progress shown on busy icon, -1 when it is not shown
+	usage           int                   // disk space usage shown on idle icon, -1 when it is not shown
+	rendered        map[overlayKey][]byte // cache of icons with bars
+	setFunc         func([]byte)          // function to set icon
+	interval        time.Duration         // interval between busy animation frames
+	frames          int                   // number of used busy animation frames, 0 means all frames of theme
+	reduced         bool                  // reduced motion mode: static busy icon
+	stopAnimation   func()                // stops the running busy animation, nil when animation is not running
 }
 
 // NewIcon initializes the icon helper, sets 'paused' icon ac initial and returns the helper.
 // Use icon.Close() for properly utilization of the icon helper resources.
-// The helper provides 'paused' 'idle' 'error' icons and animated 'busy' icon. In additional it provides LogoIcon.
-// Icons 'paused' 'idle' 'error' and 'busy' are provided in one of two themes: "light" or "dark" for light or dark DE themes.
+// The helper provides 'paused' 'idle' 'error' 'offline' 'auth' 'unknown' icons and animated 'busy' icon. In additional
+// it provides LogoIcon. Status icons are provided in one of two built-in themes: "light" or "dark" for light or dark
+// DE themes, or in one of user themes loaded by LoadThemes. The "dark" theme is used when the theme is not available.
 func NewIcon(theme string, setFunc func([]byte)) *Icon {
-	ctx, cancel := context.WithCancel(context.Background())
 	i := &Icon{
+		iconsSet:        darkSet,
 		currentStatus:   "paused",
 		currentBusyIcon: 0,
+		progress:        -1,
+		usage:           -1,
 		LogoIcon:        logo,
 		setFunc:         setFunc,
-		ticker:          time.NewTicker(time.Hour),
-		stopper:         cancel,
+		interval:        defaultInterval,
+	}
+	if err := i.SetTheme(theme); err != nil {
+		i.setIcon() // the dark theme is kept for not available theme
 	}
-	i.ticker.Stop()
-	i.SetTheme(theme)
-	go i.loop(ctx)
 	return i
 }
 
-// SetTheme select one of the icons' themes: "light" or "dark" and update icon from new theme via setFunc.
-func (i *Icon) SetTheme(theme string) {
+// SetAnimation sets the interval between busy animation frames and the number of used frames of the theme (0 means
+// all frames). In reduced motion mode the busy icon is static: only the first frame is shown.
+func (i *Icon) SetAnimation(interval time.Duration, frames int, reduced bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if interval == i.interval && frames == i.frames && reduced == i.reduced {
+		return
+	}
+	i.interval, i.frames, i.reduced = interval, frames, reduced
+	i.animate()
+	i.setIcon()
+}
+
+// frameCount returns the number of shown busy animation frames
+func (i *Icon) frameCount() int {
+	switch n := len(i.busyIcons); {
+	case i.reduced:
+		return 1
+	case i.frames > 0 && i.frames < n:
+		return i.frames
+	default:
+		return n
+	}
+}
+
+// animate (re)starts the busy animation when the icon is busy and it has to be animated, otherwise it stops
+// the animation. The animation goroutine is running only while the animated busy icon is shown.
+func (i *Icon) animate() {
+	if i.stopAnimation != nil {
+		i.stopAnimation()
+		i.stopAnimation = nil
+	}
+	i.currentBusyIcon %= i.frameCount()
+	if i.currentStatus != "busy" || i.frameCount() < 2 || i.interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	i.stopAnimation = cancel
+	go i.loop(ctx, i.interval)
+}
+
+// SetTheme selects one of the icons' themes: "light", "dark" or loaded user theme and updates icon from new theme
+// via setFunc. It returns error and keeps the current theme when the theme is not available.
+func (i *Icon) SetTheme(theme string) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
-	switch theme {
-	case "light":
+	switch set, ok := i.themes[theme]; {
+	case theme == "light":
 		i.iconsSet = lightSet
-	case "dark":
+	case theme == "dark":
 		i.iconsSet = darkSet
+	case ok:
+		i.iconsSet = set
+	default:
+		return fmt.Errorf("theme '%s' is not available", theme)
 	}
+	clear(i.rendered)
+	i.animate() // the new theme can have another number of animation frames
 	i.setIcon()
+	return nil
 }
 
 // setIcon sets the current icon image via i.SetFunc
 func (i *Icon) setIcon() {
 	switch i.currentStatus {
 	case "busy":
-		i.setFunc(i.busyIcons[i.currentBusyIcon])
+		i.setFunc(i.overlay(i.busyIcons[i.currentBusyIcon], i.progress))
+	case "idle":
+		i.setFunc(i.overlay(i.idleIcon, i.usage))
+	default:
+		i.setFunc(i.statusIcon(i.currentStatus))
+	}
+}
+
+// SetProgress sets the synchronization progress in percents that is shown as bar on the busy icon.
+// Negative value hides the bar.
+func (i *Icon) SetProgress(progress int) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if progress == i.progress {
+		return
+	}
+	i.progress = progress
+	if i.currentStatus == "busy" {
+		i.setIcon()
+	}
+}
+
+// SetUsage sets the disk space usage in percents that is shown as bar on the idle icon. Negative value hides the bar.
+func (i *Icon) SetUsage(usage int) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if usage == i.usage {
+		return
+	}
+	i.usage = usage
+	if i.currentStatus == "idle" {
+		i.setIcon()
+	}
+}
+
+// statusIcon returns the static icon of current theme for status. The unknown icon is returned for
+// unrecognized status.
+func (i *Icon) statusIcon(status string) []byte {
+	switch status {
+	case "busy":
+		return i.busyIcons[0]
 	case "idle":
-		i.setFunc(i.idleIcon)
+		return i.idleIcon
 	case "paused":
-		i.setFunc(i.pauseIcon)
+		return i.pauseIcon
 	case "error":
-		i.setFunc(i.errorIcon)
+		return i.errorIcon
+	case "offline":
+		return i.offlineIcon
+	case "auth":
+		return i.authIcon
 	}
+	return i.unknownIcon
+}
+
+// StatusIcon returns the icon of current theme for status "busy" (the first animation frame), "idle", "paused",
+// "error", "offline", "auth" and "unknown". The unknown icon is returned for unrecognized status.
+func (i *Icon) StatusIcon(status string) []byte {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.statusIcon(status)
 }
 
-// Set sets the icon for status "busy" (animated), "idle", "paused" and "error"
+// Set sets the icon for status "busy" (animated), "idle", "paused", "error", "offline", "auth" and "unknown".
+// Unrecognized status is displayed with the unknown icon.
 func (i *Icon) Set(status string) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
-	if i.currentStatus != "busy" && status == "busy" { // not busy -> busy
-		i.ticker.Reset(interval)
-	}
-	if status != "busy" && i.currentStatus == "busy" { // busy -> not busy
-		i.ticker.Stop()
-	}
+	busy := i.currentStatus == "busy"
 	i.currentStatus = status
+	if busy != (status == "busy") { // busy animation is started or stopped
+		i.animate()
+	}
 	i.setIcon()
 }
 
-func (i *Icon) loop(ctx context.Context) {
+// loop switches the busy animation frames until the context is canceled
+func (i *Icon) loop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-i.ticker.C:
+		case <-ticker.C:
 			i.lock.Lock()
-			i.currentBusyIcon = (i.currentBusyIcon + 1) % busyIconsCnt
-			i.setIcon()
+			if ctx.Err() == nil { // the animation can be stopped while waiting for the lock
+				i.currentBusyIcon = (i.currentBusyIcon + 1) % i.frameCount()
+				i.setIcon()
+			}
 			i.lock.Unlock()
 		case <-ctx.Done():
 			return
@@ -120,8 +243,12 @@ func (i *Icon) loop(ctx context.Context) {
 	}
 }
 
-// Close stops internal loop
+// Close stops the busy animation
 func (i *Icon) Close() {
-	i.ticker.Stop()
-	i.stopper()
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.stopAnimation != nil {
+		i.stopAnimation()
+		i.stopAnimation = nil
+	}
 }
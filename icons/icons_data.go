@@ -5,6 +5,9 @@ import (
 )
 
 var (
+	//go:embed img/darkAuth.png
+	darkAuth []byte
+
 	//go:embed img/darkBusy1.png
 	darkBusy1 []byte
 
@@ -26,9 +29,18 @@ var (
 	//go:embed img/darkIdle.png
 	darkIdle []byte
 
+	//go:embed img/darkOffline.png
+	darkOffline []byte
+
 	//go:embed img/darkPause.png
 	darkPause []byte
 
+	//go:embed img/darkUnknown.png
+	darkUnknown []byte
+
+	//go:embed img/lightAuth.png
+	lightAuth []byte
+
 	//go:embed img/lightBusy1.png
 	lightBusy1 []byte
 
@@ -50,9 +62,15 @@ var (
 	//go:embed img/lightIdle.png
 	lightIdle []byte
 
+	//go:embed img/lightOffline.png
+	lightOffline []byte
+
 	//go:embed img/lightPause.png
 	lightPause []byte
 
+	//go:embed img/lightUnknown.png
+	lightUnknown []byte
+
 	//go:embed img/logo.png
 	logo []byte
 )
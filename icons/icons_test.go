@@ -37,7 +37,7 @@ func TestNewIcon(t *testing.T) {
 	assert.Equal(t, darkError, i.errorIcon)
 	assert.Equal(t, darkIdle, i.idleIcon)
 	assert.Equal(t, darkPause, i.pauseIcon)
-	assert.Equal(t, [5][]byte{darkBusy1, darkBusy2, darkBusy3, darkBusy4, darkBusy5}, i.busyIcons)
+	assert.Equal(t, [][]byte{darkBusy1, darkBusy2, darkBusy3, darkBusy4, darkBusy5}, i.busyIcons)
 }
 
 func TestSetTheme(t *testing.T) {
@@ -49,7 +49,7 @@ func TestSetTheme(t *testing.T) {
 	assert.Equal(t, lightError, i.errorIcon)
 	assert.Equal(t, lightIdle, i.idleIcon)
 	assert.Equal(t, lightPause, i.pauseIcon)
-	assert.Equal(t, [5][]byte{lightBusy1, lightBusy2, lightBusy3, lightBusy4, lightBusy5}, i.busyIcons)
+	assert.Equal(t, [][]byte{lightBusy1, lightBusy2, lightBusy3, lightBusy4, lightBusy5}, i.busyIcons)
 	i.Set("idle")
 	assert.Equal(t, lightIdle, mi.get())
 	i.SetTheme("dark")
@@ -74,10 +74,32 @@ func TestSet(t *testing.T) {
 	assert.Equal(t, lightIdle, mi.get())
 	i.Set("error")
 	assert.Equal(t, lightError, mi.get())
+	i.Set("offline")
+	assert.Equal(t, lightOffline, mi.get())
+	i.Set("auth")
+	assert.Equal(t, lightAuth, mi.get())
+	i.Set("unknown")
+	assert.Equal(t, lightUnknown, mi.get())
+	i.Set("rebooting")
+	assert.Equal(t, lightUnknown, mi.get())
+}
+
+func TestStatusIcon(t *testing.T) {
+	i := NewIcon("light", mi.set)
+	defer i.Close()
+	assert.Equal(t, lightBusy1, i.StatusIcon("busy"))
+	assert.Equal(t, lightIdle, i.StatusIcon("idle"))
+	assert.Equal(t, lightPause, i.StatusIcon("paused"))
+	assert.Equal(t, lightError, i.StatusIcon("error"))
+	assert.Equal(t, lightOffline, i.StatusIcon("offline"))
+	assert.Equal(t, lightAuth, i.StatusIcon("auth"))
+	assert.Equal(t, lightUnknown, i.StatusIcon("unknown"))
+	assert.Equal(t, lightUnknown, i.StatusIcon("rebooting"))
+	assert.Equal(t, lightPause, mi.get()) // current icon is not changed
 }
 
 func TestAnimation(t *testing.T) {
-	interval = 10 * time.Millisecond
+	interval := 10 * time.Millisecond
 	tick := time.Millisecond
 	waitFor := interval + 5*tick
 	event := func(i []byte) func() bool {
@@ -87,11 +109,40 @@ func TestAnimation(t *testing.T) {
 	i := NewIcon("dark", mi.set)
 	require.NotNil(t, i)
 	defer i.Close()
+	require.False(t, animated(i))
+	i.SetAnimation(interval, 0, false)
 	i.Set("busy")
+	assert.True(t, animated(i))
 	assert.Equal(t, darkBusy1, mi.get())
 	assert.Eventually(t, event(darkBusy2), waitFor, tick)
 	assert.Eventually(t, event(darkBusy3), waitFor, tick)
 	assert.Eventually(t, event(darkBusy4), waitFor, tick)
 	assert.Eventually(t, event(darkBusy5), waitFor, tick)
 	assert.Eventually(t, event(darkBusy1), waitFor, tick)
+	i.Set("idle")
+	assert.False(t, animated(i)) // animation is not running when icon is not busy
+
+	t.Run("frames", func(t *testing.T) {
+		i.SetAnimation(interval, 2, false)
+		i.Set("busy")
+		assert.Eventually(t, event(darkBusy2), waitFor, tick)
+		assert.Eventually(t, event(darkBusy1), waitFor, tick) // the third frame is skipped
+		i.Set("idle")
+	})
+	t.Run("reduced motion", func(t *testing.T) {
+		i.SetAnimation(interval, 0, true)
+		i.Set("busy")
+		assert.False(t, animated(i))
+		assert.Equal(t, darkBusy1, mi.get())
+		i.SetAnimation(interval, 0, false) // animation is started when reduced motion is switched off
+		assert.True(t, animated(i))
+		assert.Eventually(t, event(darkBusy2), waitFor, tick)
+	})
+}
+
+// animated returns true when the busy animation is running
+func animated(i *Icon) bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.stopAnimation != nil
 }
@@ -0,0 +1,93 @@
+package icons
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// barHeightDiv is the divider of icon height that gives the height of overlay bar
+const barHeightDiv = 8
+
+var (
+	lightBar = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff} // bar color for light icons (dark panel)
+	darkBar  = color.NRGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff} // bar color for dark icons (light panel)
+)
+
+// overlayKey is the key of rendered icons cache
+type overlayKey struct {
+	base  *byte // the first byte of base icon data identifies the base icon
+	value int   // bar value in percents
+}
+
+// encoder encodes the rendered icons. The speed is preferred as icons are small.
+var encoder = png.Encoder{CompressionLevel: png.BestSpeed}
+
+// overlay returns the base icon with the bar of value percents. It returns the base icon when value is negative
+// or the icon can't be rendered. The rendered icons are cached until the theme is changed.
+func (i *Icon) overlay(base []byte, value int) []byte {
+	if value < 0 || len(base) == 0 {
+		return base
+	}
+	key := overlayKey{base: &base[0], value: min(value, 100)}
+	if data, ok := i.rendered[key]; ok {
+		return data
+	}
+	data, err := renderBar(base, key.value)
+	if err != nil {
+		return base
+	}
+	if i.rendered == nil {
+		i.rendered = make(map[overlayKey][]byte)
+	}
+	i.rendered[key] = data
+	return data
+}
+
+// renderBar draws the bar at the bottom of base icon: the track over the whole icon width and the filled part of
+// value percents. The bar color is selected by the brightness of icon. It returns png data of rendered icon.
+func renderBar(base []byte, value int) ([]byte, error) {
+	src, err := png.Decode(bytes.NewReader(base))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	img := image.NewNRGBA(b)
+	draw.Draw(img, b, src, b.Min, draw.Src)
+	fg := barColor(src)
+	track := image.Rect(b.Min.X, b.Max.Y-max(b.Dy()/barHeightDiv, 2), b.Max.X, b.Max.Y)
+	bg := fg
+	bg.A = 0x60
+	draw.Draw(img, track, &image.Uniform{C: bg}, image.Point{}, draw.Over)
+	fill := track
+	fill.Max.X = track.Min.X + track.Dx()*value/100
+	draw.Draw(img, fill, &image.Uniform{C: fg}, image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// barColor returns the light bar color for bright icon and dark bar color for dark icon. The brightness is
+// the average luminance of visible pixels.
+func barColor(img image.Image) color.NRGBA {
+	var sum, cnt uint64
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+			sum += uint64(color.GrayModel.Convert(color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xff}).(color.Gray).Y)
+			cnt++
+		}
+	}
+	if cnt > 0 && sum/cnt >= 0x80 {
+		return lightBar
+	}
+	return darkBar
+}
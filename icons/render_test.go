@@ -0,0 +1,86 @@
+package icons
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, data []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
+
+func nrgba(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestRenderBar(t *testing.T) {
+	src := decode(t, darkIdle)
+	b := src.Bounds()
+	bottom := b.Max.Y - 1
+	for _, value := range []int{0, 25, 100} {
+		data, err := renderBar(darkIdle, value)
+		require.NoError(t, err)
+		img := decode(t, data)
+		require.Equal(t, b, img.Bounds())
+		filled := b.Dx() * value / 100
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if x < filled {
+				require.Equal(t, lightBar, nrgba(img, x, bottom), "value %d x %d", value, x)
+			} else {
+				require.NotEqual(t, lightBar, nrgba(img, x, bottom), "value %d x %d", value, x)
+			}
+		}
+		bar := b.Max.Y - b.Dy()/barHeightDiv
+		for y := b.Min.Y; y < bar; y++ { // the icon above the bar is not changed
+			for x := b.Min.X; x < b.Max.X; x++ {
+				require.Equal(t, nrgba(src, x, y), nrgba(img, x, y))
+			}
+		}
+	}
+	_, err := renderBar([]byte("not png"), 50)
+	require.Error(t, err)
+}
+
+func TestBarColor(t *testing.T) {
+	require.Equal(t, lightBar, barColor(decode(t, darkIdle)))
+	require.Equal(t, darkBar, barColor(decode(t, lightIdle)))
+}
+
+// cached returns the number of cached rendered icons
+func cached(i *Icon) int {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return len(i.rendered)
+}
+
+func TestOverlay(t *testing.T) {
+	i := NewIcon("dark", mi.set)
+	defer i.Close()
+	i.Set("idle")
+	i.SetUsage(40)
+	first := mi.get()
+	require.NotEqual(t, darkIdle, first)
+	i.SetUsage(41)
+	i.SetUsage(40)
+	require.Same(t, &first[0], &mi.get()[0]) // cached icon is used
+	require.Equal(t, 2, cached(i))
+	i.SetUsage(-1)
+	require.Equal(t, darkIdle, mi.get())
+
+	i.SetProgress(80)
+	require.Equal(t, darkIdle, mi.get()) // progress is shown on busy icon only
+	i.Set("busy")
+	require.NotContains(t, [][]byte{darkBusy1, darkBusy2, darkBusy3, darkBusy4, darkBusy5}, mi.get())
+	i.Set("error")
+	require.NoError(t, i.SetTheme("light"))
+	require.Zero(t, cached(i)) // cache is reset on theme change
+	i.SetUsage(50)
+	require.Equal(t, lightError, mi.get())
+}
@@ -0,0 +1,110 @@
+package icons
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// builtinThemes are the names of embedded themes
+var builtinThemes = []string{"dark", "light"}
+
+// reservedThemes are the names that can't be used for user themes: built-in themes and "auto" theme that is
+// resolved by application into one of the available themes
+var reservedThemes = append(slices.Clone(builtinThemes), "auto")
+
+// themeFiles are the names of required static icon files of user theme
+var themeFiles = []string{"Idle.png", "Pause.png", "Error.png", "Offline.png", "Auth.png", "Unknown.png"}
+
+// LoadThemes loads the user themes from the subdirectories of dir. Each subdirectory is a theme with the directory
+// name as theme name. The theme must contain all the static icons and at least one busy animation frame (see
+// img/readme.md). The loaded themes replace the previously loaded ones. The invalid themes are skipped and
+// reported by the returned error. The missing dir is not an error, it means that there are no user themes.
+func (i *Icon) LoadThemes(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var errs []error
+	themes := make(map[string]*iconsSet)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if slices.Contains(reservedThemes, name) {
+			errs = append(errs, fmt.Errorf("theme '%s': the name is reserved", name))
+			continue
+		}
+		set, err := loadTheme(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme '%s': %w", name, err))
+			continue
+		}
+		themes[name] = set
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.themes = themes
+	return errors.Join(errs...)
+}
+
+// loadTheme reads the icons set from the theme directory
+func loadTheme(dir string) (*iconsSet, error) {
+	icons := make([][]byte, len(themeFiles))
+	for n, name := range themeFiles {
+		data, err := readIcon(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		icons[n] = data
+	}
+	set := &iconsSet{
+		idleIcon:    icons[0],
+		pauseIcon:   icons[1],
+		errorIcon:   icons[2],
+		offlineIcon: icons[3],
+		authIcon:    icons[4],
+		unknownIcon: icons[5],
+	}
+	for n := 1; ; n++ {
+		data, err := readIcon(filepath.Join(dir, fmt.Sprintf("Busy%d.png", n)))
+		if errors.Is(err, os.ErrNotExist) && n > 1 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		set.busyIcons = append(set.busyIcons, data)
+	}
+	return set, nil
+}
+
+// readIcon reads the icon file and checks that it is png image. The image is fully decoded to reject
+// the truncated or corrupted files as the icons are also converted to pixels for notifications.
+func readIcon(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Themes returns the names of available themes: the built-in themes and the loaded user themes in alphabetical order
+func (i *Icon) Themes() []string {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	names := make([]string, 0, len(i.themes))
+	for name := range i.themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return append(slices.Clone(builtinThemes), names...)
+}
@@ -0,0 +1,66 @@
+package icons
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTheme writes the theme files into dir/name using the light theme icons
+func writeTheme(t *testing.T, dir, name string, busyFrames int, skip string) {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(path, 0o755))
+	files := map[string][]byte{
+		"Idle.png":    lightIdle,
+		"Pause.png":   lightPause,
+		"Error.png":   lightError,
+		"Offline.png": lightOffline,
+		"Auth.png":    lightAuth,
+		"Unknown.png": lightUnknown,
+	}
+	busy := [][]byte{lightBusy1, lightBusy2, lightBusy3, lightBusy4, lightBusy5}
+	for n := range busyFrames {
+		files[fmt.Sprintf("Busy%d.png", n+1)] = busy[n%len(busy)]
+	}
+	delete(files, skip)
+	for f, data := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(path, f), data, 0o644))
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	writeTheme(t, dir, "mono", 3, "")
+	writeTheme(t, dir, "broken", 2, "Auth.png")
+	writeTheme(t, dir, "nobusy", 0, "")
+	writeTheme(t, dir, "dark", 5, "")
+	writeTheme(t, dir, "notpng", 1, "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notpng", "Idle.png"), []byte("text"), 0o644))
+	writeTheme(t, dir, "truncated", 1, "")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "truncated", "Idle.png"), lightIdle[:len(lightIdle)/2], 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.png"), lightIdle, 0o644))
+
+	i := NewIcon("mono", mi.set)
+	defer i.Close()
+	require.Equal(t, darkPause, mi.get()) // not loaded theme is replaced by dark one
+	err := i.LoadThemes(dir)
+	require.ErrorContains(t, err, "theme 'broken': ")
+	require.ErrorContains(t, err, "Auth.png")
+	require.ErrorContains(t, err, "theme 'nobusy': ")
+	require.ErrorContains(t, err, "theme 'dark': the name is reserved")
+	require.ErrorContains(t, err, "theme 'notpng': ")
+	require.ErrorContains(t, err, "theme 'truncated': ")
+	require.Equal(t, []string{"dark", "light", "mono"}, i.Themes())
+
+	require.NoError(t, i.SetTheme("mono"))
+	require.Equal(t, lightPause, mi.get())
+	require.Len(t, i.busyIcons, 3)
+	require.EqualError(t, i.SetTheme("broken"), "theme 'broken' is not available")
+	require.Equal(t, lightPause, mi.get()) // current theme is kept
+
+	require.NoError(t, i.LoadThemes(filepath.Join(dir, "missing")))
+	require.Equal(t, []string{"dark", "light"}, i.Themes())
+}
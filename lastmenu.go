@@ -0,0 +1,219 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slytomcat/systray"
+	"github.com/slytomcat/yd-go/tools"
+	"github.com/slytomcat/yd-go/ydisk"
+)
+
+// Actions of last synchronized item in order of lastActions
+const (
+	lastOpen     = iota // open the item by default application
+	lastShow            // show the item in its folder
+	lastCopyPath        // copy the item path to the clipboard
+	lastCopyLink        // publish the item and copy its public link to the clipboard
+	lastExclude         // exclude the item folder from synchronization
+)
+
+// lastActions are the menu titles of last synchronized item actions
+var lastActions = [...]string{"Open", "Show in folder", "Copy path", "Copy public link", "Exclude folder"}
+
+// lastEntry is the sub-menu of last synchronized item with the item actions
+type lastEntry struct {
+	item    *systray.MenuItem                   // item sub-menu
+	actions [len(lastActions)]*systray.MenuItem // action menu items in order of lastActions
+	path    string                              // item path relative to the synchronized folder
+	dir     bool                                // the item is a folder
+}
+
+// lastGroup is the sub-menu of folder with the last synchronized items from this folder
+type lastGroup struct {
+	item    *systray.MenuItem // folder sub-menu
+	entries []*lastEntry      // item entries
+}
+
+// lastClick is the click of last synchronized item action
+type lastClick struct {
+	entry  *lastEntry
+	action int // one of lastOpen...lastExclude
+}
+
+// lastFolder is the folder with the last synchronized items from this folder
+type lastFolder struct {
+	dir   string   // folder path relative to the synchronized folder, empty for the synchronized folder
+	items []string // item paths relative to the synchronized folder
+}
+
+// newLastEntry adds the entry of last synchronized item to the parent sub-menu
+func (i *indicator) newLastEntry(parent *systray.MenuItem) *lastEntry {
+	e := &lastEntry{item: parent.AddSubMenuItem("", "")}
+	for n, title := range lastActions {
+		mi := e.item.AddSubMenuItem(i.msg(title), "")
+		go func() {
+			for range mi.ClickedCh {
+				i.menu.lastCh <- lastClick{entry: e, action: n}
+			}
+		}()
+		e.actions[n] = mi
+	}
+	return e
+}
+
+// setLastEntry shows the item in the entry with the title and enables the actions that are available for the item.
+// The missing item can't be opened, shown or published. The items can't be copied when there is no clipboard tool.
+func (i *indicator) setLastEntry(e *lastEntry, item, title string) {
+	info, err := os.Stat(filepath.Join(i.yd.Path, item))
+	exists := err == nil
+	e.path, e.dir = item, exists && info.IsDir()
+	e.item.SetTitle(fileGlyph(item, e.dir) + " " + title)
+	setEnabled(e.actions[lastOpen], exists)
+	setEnabled(e.actions[lastShow], exists)
+	setEnabled(e.actions[lastCopyPath], i.canCopy())
+	setEnabled(e.actions[lastCopyLink], exists && i.canCopy())
+	setEnabled(e.actions[lastExclude], excludedFolder(item, e.dir) != "")
+	e.item.Show()
+}
+
+// updateLast shows the last synchronized items in menu: the configured number of items as the list or grouped
+// by folders
+func (i *indicator) updateLast(items []string) {
+	if i.menu == nil {
+		return
+	}
+	i.menu.lastList = items
+	lc := i.cfg.GetLast()
+	items = items[:min(len(items), lc.Length())]
+	list, groups := items, []lastFolder(nil)
+	if lc.Group {
+		list, groups = nil, groupByFolder(items)
+	}
+	for len(i.menu.lastItems) < len(list) {
+		i.menu.lastItems = append(i.menu.lastItems, i.newLastEntry(i.menu.last))
+	}
+	for n, e := range i.menu.lastItems {
+		if n < len(list) {
+			i.setLastEntry(e, list[n], tools.MakeTitle(list[n], 40))
+		} else {
+			e.item.Hide()
+		}
+	}
+	for len(i.menu.lastGroups) < len(groups) {
+		i.menu.lastGroups = append(i.menu.lastGroups, &lastGroup{item: i.menu.last.AddSubMenuItem("", "")})
+	}
+	for n, g := range i.menu.lastGroups {
+		if n >= len(groups) {
+			g.item.Hide()
+			continue
+		}
+		title := filepath.Base(i.yd.Path)
+		if groups[n].dir != "" {
+			title = tools.MakeTitle(groups[n].dir, 40)
+		}
+		g.item.SetTitle(fileGlyph("", true) + " " + title)
+		for len(g.entries) < len(groups[n].items) {
+			g.entries = append(g.entries, i.newLastEntry(g.item))
+		}
+		for m, e := range g.entries {
+			if m < len(groups[n].items) {
+				item := groups[n].items[m]
+				i.setLastEntry(e, item, tools.MakeTitle(filepath.Base(item), 40))
+			} else {
+				e.item.Hide()
+			}
+		}
+		g.item.Show()
+	}
+	setEnabled(i.menu.last, len(items) > 0)
+	i.menu.last.Show() // to update parent item view
+}
+
+// handleLastClick performs the action of last synchronized item. The publishing and the exclusion are performed
+// in background as they call the daemon.
+func (i *indicator) handleLastClick(c lastClick) {
+	path := filepath.Join(i.yd.Path, c.entry.path)
+	i.log.Debug("last_action", "action", lastActions[c.action], "path", path)
+	switch c.action {
+	case lastOpen:
+		i.openPath(path)
+	case lastShow:
+		i.showInFolder(path)
+	case lastCopyPath:
+		i.copyText(path, i.msg("Path copied to clipboard"))
+	case lastCopyLink:
+		message := i.msg("Public link copied to clipboard")
+		go func(yd *ydisk.YDisk) {
+			link, err := yd.Publish(path)
+			if err != nil {
+				i.log.Error("copy_link", "path", path, "error", err)
+				return
+			}
+			i.copyText(link, message)
+		}(i.yd)
+	case lastExclude:
+		dir := excludedFolder(c.entry.path, c.entry.dir)
+		go func(yd *ydisk.YDisk) {
+			if err := yd.Exclude(dir); err != nil {
+				i.log.Error("exclude", "dir", dir, "error", err)
+			}
+		}(i.yd)
+	}
+}
+
+// groupByFolder returns the items grouped by their folders in order of the first item of each folder
+func groupByFolder(items []string) []lastFolder {
+	var folders []lastFolder
+	index := make(map[string]int)
+	for _, item := range items {
+		dir := filepath.Dir(item)
+		if dir == "." {
+			dir = ""
+		}
+		n, ok := index[dir]
+		if !ok {
+			n = len(folders)
+			index[dir] = n
+			folders = append(folders, lastFolder{dir: dir})
+		}
+		folders[n].items = append(folders[n].items, item)
+	}
+	return folders
+}
+
+// excludedFolder returns the folder that is excluded from synchronization by the exclusion of item: the item
+// itself when it is a folder or the item folder otherwise. It returns empty string for the file in synchronized
+// folder as the synchronized folder can't be excluded.
+func excludedFolder(item string, dir bool) string {
+	if dir {
+		return item
+	}
+	if folder := filepath.Dir(item); folder != "." {
+		return folder
+	}
+	return ""
+}
+
+// fileGlyph returns the glyph of file type by the file name extension or the folder glyph
+func fileGlyph(name string, dir bool) string {
+	if dir {
+		return "📁"
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".bmp", ".svg", ".webp", ".heic", ".tif", ".tiff":
+		return "🖼"
+	case ".mp3", ".ogg", ".flac", ".wav", ".m4a", ".aac", ".opus":
+		return "🎵"
+	case ".mp4", ".mkv", ".avi", ".mov", ".webm", ".wmv", ".mpg", ".mpeg":
+		return "🎬"
+	case ".zip", ".rar", ".7z", ".tar", ".gz", ".bz2", ".xz", ".zst":
+		return "📦"
+	case ".xls", ".xlsx", ".ods", ".csv":
+		return "📊"
+	case ".doc", ".docx", ".odt", ".pdf", ".rtf", ".txt", ".md":
+		return "📝"
+	}
+	return "📄"
+}
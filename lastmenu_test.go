@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupByFolder(t *testing.T) {
+	require.Empty(t, groupByFolder(nil))
+	require.Equal(t, []lastFolder{
+		{dir: "docs", items: []string{"docs/a.txt", "docs/b.txt"}},
+		{dir: "", items: []string{"c.txt", "photos"}},
+		{dir: "photos/2024", items: []string{"photos/2024/d.jpg"}},
+	}, groupByFolder([]string{"docs/a.txt", "c.txt", "photos/2024/d.jpg", "docs/b.txt", "photos"}))
+}
+
+func TestExcludedFolder(t *testing.T) {
+	require.Equal(t, "docs", excludedFolder("docs/a.txt", false))
+	require.Equal(t, "docs/old", excludedFolder("docs/old", true))
+	require.Equal(t, "photos", excludedFolder("photos", true))
+	require.Empty(t, excludedFolder("a.txt", false))
+}
+
+func TestFileGlyph(t *testing.T) {
+	require.Equal(t, "📁", fileGlyph("photos.jpg", true))
+	require.Equal(t, "🖼", fileGlyph("photos/IMG_1.JPG", false))
+	require.Equal(t, "🎵", fileGlyph("song.mp3", false))
+	require.Equal(t, "🎬", fileGlyph("movie.mkv", false))
+	require.Equal(t, "📦", fileGlyph("backup.tar.gz", false))
+	require.Equal(t, "📊", fileGlyph("report.xlsx", false))
+	require.Equal(t, "📝", fileGlyph("notes.md", false))
+	require.Equal(t, "📄", fileGlyph("Makefile", false))
+}
@@ -2,10 +2,16 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/slytomcat/yd-go/hooks"
+	"github.com/slytomcat/yd-go/notify"
 	"github.com/slytomcat/yd-go/tools"
+	"github.com/slytomcat/yd-go/ydisk"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 func TestSetupLocalization(t *testing.T) {
@@ -26,3 +32,111 @@ func TestJoinNonEmpty(t *testing.T) {
 	require.Equal(t, "a b c", joinNonEmpty("", "a", "", "", "b", "", "c", "", ""))
 	require.Equal(t, "", joinNonEmpty("", "", ""))
 }
+
+func TestStatusEvent(t *testing.T) {
+	tests := []struct {
+		prev, stat, event string
+	}{
+		{"unknown", "none", ""},
+		{"idle", "none", hooks.DaemonStopped},
+		{"none", "idle", hooks.DaemonStarted},
+		{"idle", "busy", hooks.SyncStarted},
+		{"busy", "idle", hooks.SyncFinished},
+		{"busy", "busy", ""},
+		{"unknown", "idle", ""},
+	}
+	for _, tc := range tests {
+		require.Equal(t, tc.event, statusEvent(&ydisk.YDvals{Prev: tc.prev, Stat: tc.stat}), tc.prev+"->"+tc.stat)
+	}
+}
+
+func TestNewItems(t *testing.T) {
+	require.Equal(t, []string{"c", "d"}, newItems([]string{"a", "b"}, []string{"c", "a", "d"}))
+	require.Nil(t, newItems([]string{"a", "b"}, []string{"b"}))
+	require.Equal(t, []string{"a"}, newItems(nil, []string{"a"}))
+}
+
+func TestIconStatus(t *testing.T) {
+	require.Equal(t, "paused", iconStatus(ydisk.StatusStopped))
+	require.Equal(t, "offline", iconStatus(ydisk.StatusOffline))
+	require.Equal(t, "unknown", iconStatus(ydisk.StatusUnknown))
+}
+
+func TestCopyText(t *testing.T) {
+	cb := &tools.NoopClipboard{}
+	var sent []notify.Message
+	i := &indicator{
+		msg:        message.NewPrinter(language.English).Sprintf,
+		log:        tools.SetupLogger(false, os.Stdout),
+		clipboard:  cb,
+		notifySend: func(m notify.Message) { sent = append(sent, m) },
+	}
+	require.False(t, i.canCopy())
+	i.copyText("/home/user/Yandex.Disk/a.txt", "Path copied to clipboard")
+	require.Equal(t, "/home/user/Yandex.Disk/a.txt", cb.Text())
+	require.Len(t, sent, 1)
+	require.Equal(t, "Path copied to clipboard", sent[0].Body)
+	require.True(t, sent[0].Transient)
+}
+
+func TestClickHandlers(t *testing.T) {
+	require.Equal(t, [2]bool{false, false}, clickHandlers(tools.ClickConfig{}))
+	require.Equal(t, [2]bool{true, false}, clickHandlers(tools.ClickConfig{Primary: "open", Secondary: "menu"}))
+	require.Equal(t, [2]bool{false, true}, clickHandlers(tools.ClickConfig{Secondary: "toggle"}))
+	// the menu has to be shown by one of clicks
+	require.Equal(t, [2]bool{true, false}, clickHandlers(tools.ClickConfig{Primary: "open", Secondary: "toggle"}))
+}
+
+func TestLogConfig(t *testing.T) {
+	lc := logConfig(tools.LogConfig{Format: "json", Output: "file", Level: "warn"}, tools.Params{})
+	require.Equal(t, tools.LogConfig{Format: "json", Output: "file", Level: "warn"}, lc)
+	lc = logConfig(lc, tools.Params{Debug: true})
+	require.Equal(t, tools.LogConfig{Format: "json", Output: "file", Level: "debug"}, lc)
+	lc = logConfig(tools.LogConfig{}, tools.Params{StatusBar: "waybar"})
+	require.Equal(t, "stderr", lc.Output)
+}
+
+func TestConfigCommand(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "test.cfg")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`{"Theme":"light"}`), 0600))
+	params := tools.Params{Config: cfgPath, Command: "config"}
+	params.Args = []string{"validate"}
+	require.NoError(t, configCommand(params))
+	params.Args = []string{"show"}
+	require.NoError(t, configCommand(params))
+	params.Args = []string{"migrate"}
+	require.NoError(t, configCommand(params))
+	require.FileExists(t, cfgPath+".v0.bak")
+	params.Args = []string{"check"}
+	require.EqualError(t, configCommand(params), "unknown config subcommand 'check' (should be 'validate', 'show' or 'migrate')")
+	params.Args = nil
+	require.Error(t, configCommand(params))
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`{"Theme":"../blue","Other":1}`), 0600))
+	params.Args = []string{"validate"}
+	require.EqualError(t, configCommand(params), "Other: unknown field\nTheme: wrong theme name: '../blue' (should be 'dark', 'light', 'auto' or user theme directory name)")
+}
+
+func TestMigrateConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	legacyDir := tools.LegacyConfigDir(appName)
+	require.NoError(t, os.MkdirAll(legacyDir, 0700))
+	for _, name := range []string{"default.cfg", "work.cfg"} {
+		require.NoError(t, os.WriteFile(filepath.Join(legacyDir, name), []byte(`{"Theme":"light"}`), 0600))
+	}
+	t.Run("explicit legacy path", func(t *testing.T) {
+		for _, name := range []string{"work.cfg", "default.cfg"} {
+			migrateConfig(tools.Params{Config: filepath.Join(legacyDir, name)})
+			require.FileExists(t, filepath.Join(legacyDir, name))
+		}
+		require.NoFileExists(t, filepath.Join(tools.ConfigDir(appName), "work.cfg"))
+		require.NoFileExists(t, tools.DefaultConfig(appName))
+	})
+	t.Run("default path", func(t *testing.T) {
+		migrateConfig(tools.Params{Config: tools.DefaultConfig(appName)})
+		require.FileExists(t, tools.DefaultConfig(appName))
+		require.NoFileExists(t, filepath.Join(legacyDir, "default.cfg"))
+		require.FileExists(t, filepath.Join(legacyDir, "work.cfg"))
+	})
+}
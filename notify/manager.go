@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Sender is the notification backend
+type Sender interface {
+	SendMessage(m Message)
+	Close()
+}
+
+// Backend kinds
+const (
+	BackendServer = "server" // org.freedesktop.Notifications server
+	BackendPortal = "portal" // XDG desktop portal Notification interface
+	BackendLog    = "log"    // notifications are only logged
+)
+
+// retryInterval is the interval of attempts to connect to notification server when it is not available
+var retryInterval = 30 * time.Second
+
+// backend constructors, they are variables to be replaced in tests
+var (
+	newServer = func(app string, icon []byte, log *slog.Logger) (Sender, error) { return New(app, icon, false, -1, log) }
+	newPortal = func(app string, icon []byte, _ *slog.Logger) (Sender, error) { return NewPortal(app, icon) }
+)
+
+// logSender logs the notifications when there is no notification service
+type logSender struct {
+	log *slog.Logger
+}
+
+// SendMessage logs the message
+func (l logSender) SendMessage(m Message) {
+	l.log.Info("notification", "title", m.Title, "message", m.Body)
+}
+
+// Close does nothing
+func (logSender) Close() {}
+
+// Manager sends the notifications via the best available backend: notification server, desktop portal or log.
+// When the notification server is not available, Manager waits for its appearance on the session bus and
+// retries to connect in background. It switches back to the fallback backend when the server disappears.
+type Manager struct {
+	app     string
+	icon    []byte
+	log     *slog.Logger
+	lock    sync.Mutex // protects sender and kind
+	sender  Sender
+	kind    string
+	changes chan string
+	cancel  func()
+	done    chan struct{}
+}
+
+// NewManager creates the notifications manager with the best available backend and starts waiting for
+// the notification server when it is not available. The application is the name of application, the icon is
+// png image data to use as notification icon. Use Close to release the manager resources.
+func NewManager(application string, icon []byte, log *slog.Logger) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		app:     application,
+		icon:    icon,
+		log:     log,
+		changes: make(chan string, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	m.sender, m.kind = m.connect()
+	go m.watch(ctx)
+	return m
+}
+
+// connect returns the best available backend
+func (m *Manager) connect() (Sender, string) {
+	server, err := newServer(m.app, m.icon, m.log)
+	if err == nil {
+		m.log.Info("notifications", "backend", BackendServer)
+		return server, BackendServer
+	}
+	m.log.Warn("notifications", "status", "not_available", "error", err, "recommendation", ToolTipMsg)
+	portal, err := newPortal(m.app, m.icon, m.log)
+	if err == nil {
+		m.log.Info("notifications", "backend", BackendPortal)
+		return portal, BackendPortal
+	}
+	m.log.Debug("notifications", "portal", "not_available", "error", err)
+	m.log.Info("notifications", "backend", BackendLog)
+	return logSender{log: m.log}, BackendLog
+}
+
+// watch waits for appearance and disappearance of notification server and switches the backend
+func (m *Manager) watch(ctx context.Context) {
+	defer close(m.done)
+	owners := make(chan *dbus.Signal, 10)
+	if conn, err := dbus.ConnectSessionBus(); err == nil {
+		defer conn.Close()
+		if err := conn.AddMatchSignalContext(ctx, dbus.WithMatchInterface("org.freedesktop.DBus"),
+			dbus.WithMatchMember("NameOwnerChanged"), dbus.WithMatchArg(0, dBusDest)); err == nil {
+			conn.Signal(owners)
+		}
+	} else {
+		m.log.Debug("notifications", "watch", "not_available", "error", err)
+	}
+	retry := time.NewTicker(retryInterval)
+	defer retry.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-owners:
+			if len(s.Body) < 3 {
+				continue
+			}
+			owner, _ := s.Body[2].(string)
+			m.log.Debug("notifications", "server_owner", owner)
+			switch server := m.Backend() == BackendServer; {
+			case owner == "" && server: // the server disappeared
+				m.replace(m.connect())
+			case owner != "" && !server:
+				m.tryServer()
+			}
+		case <-retry.C:
+			if m.Backend() != BackendServer {
+				m.tryServer()
+			}
+		}
+	}
+}
+
+// tryServer switches to the notification server backend when the server is available
+func (m *Manager) tryServer() {
+	server, err := newServer(m.app, m.icon, m.log)
+	if err != nil {
+		m.log.Debug("notifications", "server", "not_available", "error", err)
+		return
+	}
+	m.log.Info("notifications", "backend", BackendServer)
+	m.replace(server, BackendServer)
+}
+
+// replace sets the new backend, closes the previous one and reports the change of backend kind
+func (m *Manager) replace(sender Sender, kind string) {
+	m.lock.Lock()
+	old, oldKind := m.sender, m.kind
+	m.sender, m.kind = sender, kind
+	m.lock.Unlock()
+	old.Close()
+	if kind != oldKind {
+		select {
+		case <-m.changes: // drop the previous unread change
+		default:
+		}
+		m.changes <- kind
+	}
+}
+
+// Backend returns the current backend kind: BackendServer, BackendPortal or BackendLog
+func (m *Manager) Backend() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.kind
+}
+
+// Changes returns the channel that receives the new backend kind when the backend is changed
+func (m *Manager) Changes() <-chan string {
+	return m.changes
+}
+
+// SendMessage sends the message via the current backend
+func (m *Manager) SendMessage(msg Message) {
+	m.lock.Lock()
+	sender := m.sender
+	m.lock.Unlock()
+	sender.SendMessage(msg)
+}
+
+// Close stops waiting for notification server and closes the current backend
+func (m *Manager) Close() {
+	m.cancel()
+	<-m.done
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sender.Close()
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	sent   []Message
+	closed atomic.Bool
+}
+
+func (f *fakeSender) SendMessage(m Message) { f.sent = append(f.sent, m) }
+func (f *fakeSender) Close()                { f.closed.Store(true) }
+
+func TestManager(t *testing.T) {
+	origServer, origPortal, origRetry := newServer, newPortal, retryInterval
+	defer func() { newServer, newPortal, retryInterval = origServer, origPortal, origRetry }()
+	var available atomic.Bool
+	server := &fakeSender{}
+	newServer = func(string, []byte, *slog.Logger) (Sender, error) {
+		if available.Load() {
+			return server, nil
+		}
+		return nil, errors.New("no server")
+	}
+	newPortal = func(string, []byte, *slog.Logger) (Sender, error) { return nil, errors.New("no portal") }
+	retryInterval = 10 * time.Millisecond
+	out := &bytes.Buffer{}
+	m := NewManager("app", nil, slog.New(slog.NewTextHandler(out, nil)))
+	require.Equal(t, BackendLog, m.Backend())
+	m.SendMessage(Message{Title: "title", Body: "logged"})
+	require.Contains(t, out.String(), "msg=notification title=title message=logged")
+	available.Store(true)
+	select {
+	case kind := <-m.Changes():
+		require.Equal(t, BackendServer, kind)
+	case <-time.After(time.Second):
+		t.Fatal("server was not connected")
+	}
+	m.SendMessage(Message{Title: "title", Body: "sent"})
+	require.Equal(t, []Message{{Title: "title", Body: "sent"}}, server.sent)
+	m.Close()
+	require.True(t, server.closed.Load())
+}
+
+func TestPortalNotification(t *testing.T) {
+	n := portalNotification(Message{Title: "title", Body: "body", Urgency: UrgencyCritical,
+		Actions: []Action{{Key: "open", Label: "Open"}}}, []byte("icon"))
+	require.Equal(t, "title", n["title"].Value())
+	require.Equal(t, "body", n["body"].Value())
+	require.Equal(t, "urgent", n["priority"].Value())
+	require.Equal(t, []map[string]dbus.Variant{{"label": dbus.MakeVariant("Open"), "action": dbus.MakeVariant("open")}}, n["buttons"].Value())
+	require.Equal(t, "(sv)", n["icon"].Signature().String())
+	n = portalNotification(Message{Urgency: UrgencyLow}, nil)
+	require.Equal(t, "low", n["priority"].Value())
+	require.NotContains(t, n, "icon")
+	require.NotContains(t, n, "buttons")
+}
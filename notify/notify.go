@@ -3,8 +3,13 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"image"
 	_ "image/png"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
 	"sync/atomic"
 
 	"github.com/godbus/dbus/v5"
@@ -18,9 +23,45 @@ type Notify struct {
 	iconHints map[string]dbus.Variant
 	replace   bool
 	time      int
+	log       *slog.Logger
 	conn      *dbus.Conn
 	connObj   dbus.BusObject
 	lastID    atomic.Uint32
+	caps      []string                        // the notification server capabilities
+	icons     map[[sha256.Size]byte]ImageData // converted message icons by the hash of icon data
+	signals   chan *dbus.Signal               // ActionInvoked and NotificationClosed signals
+	lock      sync.Mutex                      // protects handlers and icons
+	handlers  map[uint32]map[string]func()    // action handlers by notification ID and action key
+}
+
+// Urgency is the notification urgency level
+type Urgency byte
+
+// Urgency levels as they declared into D-BUS specs
+const (
+	UrgencyLow Urgency = iota
+	UrgencyNormal
+	UrgencyCritical
+)
+
+// Message is the desktop notification with per-message options. The zero values of options mean defaults.
+type Message struct {
+	Title     string
+	Body      string
+	Actions   []Action // action buttons, they are ignored when the server doesn't support actions
+	Urgency   Urgency  // urgency level (the zero value is UrgencyLow, use UrgencyNormal for regular messages)
+	Category  string   // notification category, e.g. "transfer.complete" or "transfer.error"
+	Timeout   int      // time in milliseconds after which the notification disappears, 0 means the New time value
+	Icon      []byte   // png/ico image data of message icon, nil means the New icon
+	Transient bool     // the notification bypasses the server persistence, it requires "persistence" capability
+	Resident  bool     // the notification is not removed after action invocation, it requires "persistence" capability
+}
+
+// Action is the notification action button. The Handler is called when user invokes the action.
+type Action struct {
+	Key     string // action identifier, it has to be unique within the notification
+	Label   string // localized text of the button
+	Handler func() // function to call on action invocation
 }
 
 const (
@@ -34,9 +75,10 @@ const (
 // The icon is png/ico image data to use in Send as notify message icon.
 // True value of replace means that a new notification will replace the previous one if it is still displayed.
 // The time sets the time in milliseconds after which the notification will disappear. Set it to -1 to use Desktop default settings.
+// The log is used to report the icons that can't be converted, such notifications are sent without icon.
 // It returns error in cases of D-BUS connection error or error of getting the notification server capabilities.
-func New(application string, icon []byte, replace bool, time int) (*Notify, error) {
-	conn, err := dbus.SessionBus()
+func New(application string, icon []byte, replace bool, time int, log *slog.Logger) (*Notify, error) {
+	conn, err := dbus.ConnectSessionBus() // private connection as it is closed by Close
 	if err != nil {
 		return nil, err
 	}
@@ -47,34 +89,179 @@ func New(application string, icon []byte, replace bool, time int) (*Notify, erro
 		app:     application,
 		replace: replace,
 		time:    time,
+		log:     log,
 		conn:    conn,
 		connObj: conn.Object(dBusDest, dBusPath),
+		icons:   map[[sha256.Size]byte]ImageData{},
 	}
-	if _, err = notify.Cap(); err != nil {
+	if notify.caps, err = notify.Cap(); err != nil {
+		cancel()
+		conn.Close()
 		return nil, err
 	}
 	// perform heavy staff only when service is available
-	notify.iconHints = map[string]dbus.Variant{"image-data": dbus.MakeVariant(convertToPixels(icon))}
+	notify.iconHints = map[string]dbus.Variant{}
+	if data, err := convertToPixels(icon); err == nil {
+		notify.iconHints["image-data"] = dbus.MakeVariant(data)
+	} else {
+		log.Warn("notifications", "icon", "not_converted", "error", err)
+	}
+	if notify.supports("actions") && notify.subscribe() != nil {
+		notify.caps = slices.DeleteFunc(notify.caps, func(c string) bool { return c == "actions" })
+	}
 	return notify, nil
 }
 
+// subscribe starts receiving of ActionInvoked and NotificationClosed signals
+func (n *Notify) subscribe() error {
+	for _, member := range []string{"ActionInvoked", "NotificationClosed"} {
+		if err := n.conn.AddMatchSignalContext(n.ctx, dbus.WithMatchObjectPath(dBusPath),
+			dbus.WithMatchInterface(dBusDest), dbus.WithMatchMember(member)); err != nil {
+			return err
+		}
+	}
+	n.handlers = make(map[uint32]map[string]func())
+	n.signals = make(chan *dbus.Signal, 10)
+	n.conn.Signal(n.signals)
+	go n.handleSignals()
+	return nil
+}
+
+// handleSignals calls the action handlers and forgets the handlers of closed notifications
+func (n *Notify) handleSignals() {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case s, ok := <-n.signals:
+			if !ok {
+				return
+			}
+			if s.Path != dBusPath || len(s.Body) < 2 {
+				continue
+			}
+			id, _ := s.Body[0].(uint32)
+			switch s.Name {
+			case dBusDest + ".ActionInvoked":
+				key, _ := s.Body[1].(string)
+				n.lock.Lock()
+				handler := n.handlers[id][key]
+				n.lock.Unlock()
+				if handler != nil {
+					go handler()
+				}
+			case dBusDest + ".NotificationClosed":
+				n.lock.Lock()
+				delete(n.handlers, id)
+				n.lock.Unlock()
+			}
+		}
+	}
+}
+
 // Close closes D-BUS connection. Call it on app exit or similar cases.
 func (n *Notify) Close() {
 	n.cancel()
+	if n.signals != nil {
+		n.conn.RemoveSignal(n.signals)
+	}
 	n.conn.Close()
 }
 
-// Send sends the desktop notification.
-func (n *Notify) Send(title, message string) {
+// supports returns true when the notification server has the capability
+func (n *Notify) supports(capability string) bool {
+	return slices.Contains(n.caps, capability)
+}
+
+// Send sends the desktop notification of normal urgency with optional action buttons.
+// The actions are ignored when the notification server doesn't support them.
+func (n *Notify) Send(title, message string, actions ...Action) {
+	n.SendMessage(Message{Title: title, Body: message, Actions: actions, Urgency: UrgencyNormal})
+}
+
+// hints returns the notification hints for the message options. The urgency, category and icon hints have no
+// capabilities in D-BUS specs and they are always sent, the transient and resident hints are sent only when
+// the server supports persistence.
+func (n *Notify) hints(m Message) map[string]dbus.Variant {
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(byte(m.Urgency))}
+	if len(m.Icon) > 0 {
+		if data, err := n.iconData(m.Icon); err == nil {
+			hints["image-data"] = dbus.MakeVariant(data)
+		} else {
+			n.log.Warn("notifications", "icon", "not_converted", "error", err)
+		}
+	} else {
+		maps.Copy(hints, n.iconHints)
+	}
+	if m.Category != "" {
+		hints["category"] = dbus.MakeVariant(m.Category)
+	}
+	if n.supports("persistence") {
+		if m.Transient {
+			hints["transient"] = dbus.MakeVariant(true)
+		}
+		if m.Resident && n.supports("actions") {
+			hints["resident"] = dbus.MakeVariant(true)
+		}
+	}
+	return hints
+}
+
+// maxIcons is the maximum number of cached converted icons
+const maxIcons = 16
+
+// iconData returns the converted icon. The icons are cached as they are usually the same embedded images.
+// The cache is cleared when it is full. The icons that can't be converted are not cached.
+func (n *Notify) iconData(icon []byte) (ImageData, error) {
+	key := sha256.Sum256(icon)
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if data, ok := n.icons[key]; ok {
+		return data, nil
+	}
+	data, err := convertToPixels(icon)
+	if err != nil {
+		return data, err
+	}
+	if len(n.icons) >= maxIcons {
+		clear(n.icons)
+	}
+	n.icons[key] = data
+	return data, nil
+}
+
+// SendMessage sends the desktop notification with per-message options.
+// The options that the notification server doesn't support are not sent.
+func (n *Notify) SendMessage(m Message) {
 	var last uint32
 	if n.replace {
 		last = n.lastID.Load()
 	}
-	call := n.connObj.CallWithContext(n.ctx, dBusDest+".Notify", dbus.Flags(0), n.app, last, "", title, message, []string{}, n.iconHints, n.time)
-	if call.Err == nil && n.replace {
-		n.lastID.Store(call.Body[0].(uint32))
+	keys := []string{}
+	handlers := map[string]func(){}
+	if n.supports("actions") {
+		for _, a := range m.Actions {
+			keys = append(keys, a.Key, a.Label)
+			handlers[a.Key] = a.Handler
+		}
+	}
+	timeout := n.time
+	if m.Timeout != 0 {
+		timeout = m.Timeout
+	}
+	call := n.connObj.CallWithContext(n.ctx, dBusDest+".Notify", dbus.Flags(0), n.app, last, "", m.Title, m.Body, keys, n.hints(m), timeout)
+	if call.Err != nil {
+		return // ignore the possible errors
+	}
+	id := call.Body[0].(uint32)
+	if n.replace {
+		n.lastID.Store(id)
+	}
+	if len(handlers) > 0 {
+		n.lock.Lock()
+		n.handlers[id] = handlers
+		n.lock.Unlock()
 	}
-	// ignore the rest possible errors
 }
 
 // ImageData is struct to hold image data into pix-buffer format as it declared into D-BUS specs.
@@ -86,10 +273,10 @@ type ImageData struct {
 }
 
 // convertToPixels is used to convert png/ico format into pix-buffer as it declared into D-BUS specs.
-func convertToPixels(data []byte) ImageData {
+func convertToPixels(data []byte) (ImageData, error) {
 	src, _, err := image.Decode(bytes.NewReader(data))
 	if err != nil {
-		panic(err)
+		return ImageData{}, err
 	}
 	img := image.NewRGBA(src.Bounds())
 	for x := range src.Bounds().Dx() {
@@ -105,7 +292,7 @@ func convertToPixels(data []byte) ImageData {
 		BitsPerSample: 8,
 		Channels:      4,
 		ImageData:     img.Pix,
-	}
+	}, nil
 }
 
 // Cap returns the notification server capabilities
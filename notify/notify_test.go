@@ -1,11 +1,17 @@
 package notify
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log/slog"
 	"os"
 	"path"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/godbus/dbus/v5"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +27,7 @@ func TestDBusNotify(t *testing.T) {
 	icon, err := os.ReadFile(p)
 	require.NoError(t, err)
 
-	n, err := New("appName", icon, true, -1)
+	n, err := New("appName", icon, true, -1, slog.Default())
 	require.NoError(t, err)
 	require.NotNil(t, n)
 	defer n.Close()
@@ -41,4 +47,75 @@ func TestDBusNotify(t *testing.T) {
 	time.Sleep(time.Second)
 	n.Send("title4", "message4")
 	time.Sleep(time.Second)
+	n.Send("title5", "message5", Action{Key: "ok", Label: "OK", Handler: func() {}})
+	time.Sleep(time.Second)
+}
+
+func TestHandleSignals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := &Notify{
+		ctx:      ctx,
+		signals:  make(chan *dbus.Signal),
+		handlers: map[uint32]map[string]func(){},
+	}
+	invoked := make(chan string, 1)
+	n.handlers[7] = map[string]func(){"open": func() { invoked <- "open" }}
+	go n.handleSignals()
+	n.signals <- &dbus.Signal{Path: dBusPath, Name: dBusDest + ".ActionInvoked", Body: []any{uint32(7), "unknown"}}
+	n.signals <- &dbus.Signal{Path: dBusPath, Name: dBusDest + ".ActionInvoked", Body: []any{uint32(7), "open"}}
+	select {
+	case key := <-invoked:
+		require.Equal(t, "open", key)
+	case <-time.After(time.Second):
+		t.Fatal("action handler was not called")
+	}
+	n.signals <- &dbus.Signal{Path: dBusPath, Name: dBusDest + ".NotificationClosed", Body: []any{uint32(7), uint32(2)}}
+	n.signals <- &dbus.Signal{Path: dBusPath, Name: dBusDest + ".ActionInvoked", Body: []any{uint32(7), "open"}} // wait for previous signal handling
+	n.lock.Lock()
+	require.Empty(t, n.handlers)
+	n.lock.Unlock()
+	require.Empty(t, invoked)
+}
+
+func TestHints(t *testing.T) {
+	icon, err := os.ReadFile("../icons/img/logo.png")
+	require.NoError(t, err)
+	out := &bytes.Buffer{}
+	n := &Notify{
+		log:       slog.New(slog.NewTextHandler(out, nil)),
+		iconHints: map[string]dbus.Variant{"image-data": dbus.MakeVariant("app icon")},
+		icons:     map[[sha256.Size]byte]ImageData{},
+	}
+	m := Message{Urgency: UrgencyCritical, Category: "transfer.error", Transient: true, Resident: true}
+	require.Equal(t, map[string]dbus.Variant{
+		"urgency":    dbus.MakeVariant(byte(2)),
+		"category":   dbus.MakeVariant("transfer.error"),
+		"image-data": dbus.MakeVariant("app icon"),
+	}, n.hints(m))
+	n.caps = []string{"persistence"}
+	require.Contains(t, n.hints(m), "transient")
+	require.NotContains(t, n.hints(m), "resident") // resident requires actions support
+	n.caps = append(n.caps, "actions")
+	require.Contains(t, n.hints(m), "resident")
+	m.Icon = icon
+	data := n.hints(m)["image-data"].Value().(ImageData)
+	expected, err := convertToPixels(icon)
+	require.NoError(t, err)
+	require.Equal(t, expected, data)
+	require.Len(t, n.icons, 1)
+	n.hints(m)
+	require.Len(t, n.icons, 1) // converted icon is cached
+	m.Icon = slices.Clone(icon)
+	n.hints(m)
+	require.Len(t, n.icons, 1) // the same content is cached once
+	for i := range maxIcons {
+		n.iconData(append(slices.Clone(icon), byte(i)))
+	}
+	require.LessOrEqual(t, len(n.icons), maxIcons) // the cache size is limited
+	for _, broken := range [][]byte{[]byte("text"), icon[:len(icon)/2]} {
+		m.Icon = broken
+		require.NotContains(t, n.hints(m), "image-data") // the message is sent without icon
+	}
+	require.Contains(t, out.String(), "icon=not_converted")
 }
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	portalDest  = "org.freedesktop.portal.Desktop"
+	portalPath  = "/org/freedesktop/portal/desktop"
+	portalIface = "org.freedesktop.portal.Notification"
+	portalKeep  = 32 // number of the last notifications that keep their action handlers
+)
+
+// Portal sends the notifications via the XDG desktop portal Notification interface. It is used when there is no
+// notification server on the session bus (e.g. in sandboxed environments).
+type Portal struct {
+	ctx      context.Context
+	cancel   func()
+	app      string
+	icon     []byte
+	conn     *dbus.Conn
+	connObj  dbus.BusObject
+	lastID   atomic.Uint64
+	signals  chan *dbus.Signal
+	lock     sync.Mutex                   // protects handlers and ids
+	handlers map[string]map[string]func() // action handlers by notification ID and action key
+	ids      []string                     // notification IDs in sending order
+}
+
+// NewPortal creates the portal notifications sender. The application is the name of application, the icon is
+// png image data to use as notification icon. It returns error when the portal doesn't provide the Notification
+// interface.
+func NewPortal(application string, icon []byte) (*Portal, error) {
+	conn, err := dbus.ConnectSessionBus() // private connection as it is closed by Close
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Portal{
+		ctx:      ctx,
+		cancel:   cancel,
+		app:      application,
+		icon:     icon,
+		conn:     conn,
+		connObj:  conn.Object(portalDest, portalPath),
+		handlers: make(map[string]map[string]func()),
+	}
+	if _, err := p.connObj.GetProperty(portalIface + ".version"); err != nil {
+		cancel()
+		conn.Close()
+		return nil, fmt.Errorf("notification portal is not available: %w", err)
+	}
+	if err := conn.AddMatchSignalContext(ctx, dbus.WithMatchObjectPath(portalPath),
+		dbus.WithMatchInterface(portalIface), dbus.WithMatchMember("ActionInvoked")); err == nil {
+		p.signals = make(chan *dbus.Signal, 10)
+		conn.Signal(p.signals)
+		go p.handleSignals()
+	}
+	return p, nil
+}
+
+// handleSignals calls the action handlers
+func (p *Portal) handleSignals() {
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case s, ok := <-p.signals:
+			if !ok {
+				return
+			}
+			if s.Name != portalIface+".ActionInvoked" || len(s.Body) < 2 {
+				continue
+			}
+			id, _ := s.Body[0].(string)
+			key, _ := s.Body[1].(string)
+			p.lock.Lock()
+			handler := p.handlers[id][key]
+			p.lock.Unlock()
+			if handler != nil {
+				go handler()
+			}
+		}
+	}
+}
+
+// portalPriority converts the urgency into the portal notification priority
+func portalPriority(u Urgency) string {
+	switch u {
+	case UrgencyLow:
+		return "low"
+	case UrgencyCritical:
+		return "urgent"
+	}
+	return "normal"
+}
+
+// portalNotification converts the message into the portal notification. The message icon or the default icon
+// is passed as serialized GBytesIcon.
+func portalNotification(m Message, icon []byte) map[string]dbus.Variant {
+	n := map[string]dbus.Variant{
+		"title":    dbus.MakeVariant(m.Title),
+		"body":     dbus.MakeVariant(m.Body),
+		"priority": dbus.MakeVariant(portalPriority(m.Urgency)),
+	}
+	if len(m.Icon) > 0 {
+		icon = m.Icon
+	}
+	if len(icon) > 0 {
+		n["icon"] = dbus.MakeVariant(struct {
+			Kind string
+			Data dbus.Variant
+		}{"bytes", dbus.MakeVariant(icon)})
+	}
+	if len(m.Actions) > 0 {
+		buttons := make([]map[string]dbus.Variant, len(m.Actions))
+		for i, a := range m.Actions {
+			buttons[i] = map[string]dbus.Variant{"label": dbus.MakeVariant(a.Label), "action": dbus.MakeVariant(a.Key)}
+		}
+		n["buttons"] = dbus.MakeVariant(buttons)
+	}
+	return n
+}
+
+// SendMessage sends the notification via portal. The Timeout, Category, Transient and Resident options are
+// not supported by portal and they are ignored.
+func (p *Portal) SendMessage(m Message) {
+	id := fmt.Sprintf("%s-%d", p.app, p.lastID.Add(1))
+	call := p.connObj.CallWithContext(p.ctx, portalIface+".AddNotification", dbus.Flags(0), id, portalNotification(m, p.icon))
+	if call.Err != nil || len(m.Actions) == 0 {
+		return // ignore the possible errors
+	}
+	handlers := make(map[string]func(), len(m.Actions))
+	for _, a := range m.Actions {
+		handlers[a.Key] = a.Handler
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.handlers[id] = handlers
+	p.ids = append(p.ids, id)
+	if len(p.ids) > portalKeep { // the portal doesn't report closing of notifications
+		delete(p.handlers, p.ids[0])
+		p.ids = slices.Delete(p.ids, 0, 1)
+	}
+}
+
+// Close stops the signals handling and closes D-BUS connection
+func (p *Portal) Close() {
+	p.cancel()
+	if p.signals != nil {
+		p.conn.RemoveSignal(p.signals)
+	}
+	p.conn.Close()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/slytomcat/yd-go/hooks"
+)
+
+const (
+	notifyWindow = 2 * time.Second // the time of holding the notification for coalescing with the following ones
+	notifyPeriod = time.Minute     // the rate limit period for the notifications of one event kind
+)
+
+// opposite are the status events that cancel each other
+var opposite = map[string]string{
+	hooks.DaemonStarted: hooks.DaemonStopped,
+	hooks.DaemonStopped: hooks.DaemonStarted,
+	hooks.SyncStarted:   hooks.SyncFinished,
+	hooks.SyncFinished:  hooks.SyncStarted,
+}
+
+// pendingNote is the notification that waits for the end of coalescing window
+type pendingNote struct {
+	n   notification
+	due time.Time
+}
+
+// rateLimit is the rate limiting state of one event kind
+type rateLimit struct {
+	until time.Time // end of the rate limit period
+	count int       // number of suppressed notifications
+	files int       // number of new items in suppressed notifications
+}
+
+// notifyQueue coalesces and rate-limits the event notifications. Each notification is held for the coalescing
+// window: the next notification of the same kind replaces it and the opposite status event (e.g. sync finished
+// for sync started) cancels it, so only the latest state is notified when the daemon flaps between statuses.
+// Only one notification of each event kind is sent per rate limit period, the suppressed ones are merged into
+// the summary that is sent at the end of period. The notifications are sent by the separate goroutine, so a slow
+// notification server doesn't stall the queue and the producers.
+type notifyQueue struct {
+	in      chan notification
+	done    chan struct{}
+	out     chan func()                          // the sending of notifications and summaries
+	sent    chan struct{}                        // closed when the sending goroutine is finished
+	send    func(notification)                   // sends the notification
+	summary func(event string, count, files int) // sends the summary of suppressed notifications
+	window  time.Duration
+	period  time.Duration
+	pending []pendingNote
+	limits  map[string]*rateLimit
+}
+
+// newNotifyQueue creates the notification queue and starts its loop and the sending goroutine. Use close to stop them.
+func newNotifyQueue(send func(notification), summary func(event string, count, files int), window, period time.Duration) *notifyQueue {
+	q := &notifyQueue{
+		in:     make(chan notification, 16),
+		done:   make(chan struct{}),
+		out:    make(chan func(), 16),
+		sent:   make(chan struct{}),
+		window: window,
+		period: period,
+		limits: make(map[string]*rateLimit),
+	}
+	q.send = func(n notification) { q.out <- func() { send(n) } }
+	q.summary = func(event string, count, files int) { q.out <- func() { summary(event, count, files) } }
+	go q.sender()
+	go q.loop()
+	return q
+}
+
+// push puts the notification into the queue. It doesn't block: the notification is dropped and false is returned
+// when the queue is full.
+func (q *notifyQueue) push(n notification) bool {
+	select {
+	case q.in <- n:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the queue loop and the sending goroutine. The pending notifications are delivered and the summaries
+// of suppressed notifications are sent before the stop.
+func (q *notifyQueue) close() {
+	close(q.in)
+	<-q.done
+	<-q.sent
+}
+
+// sender sends the notifications and summaries until the out channel is closed
+func (q *notifyQueue) sender() {
+	defer close(q.sent)
+	for send := range q.out {
+		send()
+	}
+}
+
+func (q *notifyQueue) loop() {
+	defer close(q.done)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	for {
+		select {
+		case n, ok := <-q.in:
+			if !ok {
+				timer.Stop()
+				q.drain(time.Now())
+				close(q.out)
+				return
+			}
+			q.add(n, time.Now())
+		case <-timer.C:
+		}
+		now := time.Now()
+		q.flush(now)
+		if next, ok := q.next(); ok {
+			timer.Reset(next.Sub(now))
+		}
+	}
+}
+
+// add puts the notification into the pending list coalescing it with the pending notifications
+func (q *notifyQueue) add(n notification, now time.Time) {
+	q.pending = slices.DeleteFunc(q.pending, func(p pendingNote) bool {
+		if p.n.event != n.event && p.n.event != opposite[n.event] {
+			return false
+		}
+		n.items = slices.Concat(p.n.items, n.items) // keep the items of the replaced or canceled notification
+		return true
+	})
+	q.pending = append(q.pending, pendingNote{n: n, due: now.Add(q.window)})
+}
+
+// flush delivers the pending notifications and sends the summaries for the finished rate limit periods
+func (q *notifyQueue) flush(now time.Time) {
+	var due []pendingNote
+	q.pending = slices.DeleteFunc(q.pending, func(p pendingNote) bool {
+		if p.due.After(now) {
+			return false
+		}
+		due = append(due, p)
+		return true
+	})
+	for _, p := range due {
+		q.deliver(p.n, now)
+	}
+	for event, l := range q.limits {
+		if l.until.After(now) {
+			continue
+		}
+		if l.count > 0 {
+			q.summary(event, l.count, l.files)
+		}
+		delete(q.limits, event)
+	}
+}
+
+// drain delivers all pending notifications and sends the summaries of all rate limit periods
+func (q *notifyQueue) drain(now time.Time) {
+	for _, p := range q.pending {
+		q.deliver(p.n, now)
+	}
+	q.pending = nil
+	for _, event := range slices.Sorted(maps.Keys(q.limits)) {
+		if l := q.limits[event]; l.count > 0 {
+			q.summary(event, l.count, l.files)
+		}
+	}
+	clear(q.limits)
+}
+
+// deliver sends the notification or counts it when the rate limit period of its event kind is not finished
+func (q *notifyQueue) deliver(n notification, now time.Time) {
+	if l, ok := q.limits[n.event]; ok {
+		l.count++
+		l.files += len(n.items)
+		return
+	}
+	q.send(n)
+	q.limits[n.event] = &rateLimit{until: now.Add(q.period)}
+}
+
+// next returns the time of the next flush. It returns false when nothing is waiting.
+func (q *notifyQueue) next() (time.Time, bool) {
+	var next time.Time
+	for _, p := range q.pending {
+		if next.IsZero() || p.due.Before(next) {
+			next = p.due
+		}
+	}
+	for _, l := range q.limits {
+		if next.IsZero() || l.until.Before(next) {
+			next = l.until
+		}
+	}
+	return next, !next.IsZero()
+}
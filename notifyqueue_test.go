@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slytomcat/yd-go/hooks"
+	"github.com/stretchr/testify/require"
+)
+
+type queueRecorder struct {
+	sent      []notification
+	summaries []string
+}
+
+func (r *queueRecorder) queue(window, period time.Duration) *notifyQueue {
+	return &notifyQueue{
+		send: func(n notification) { r.sent = append(r.sent, n) },
+		summary: func(event string, count, files int) {
+			r.summaries = append(r.summaries, fmt.Sprint(event, " ", count, " ", files))
+		},
+		window: window,
+		period: period,
+		limits: make(map[string]*rateLimit),
+	}
+}
+
+func (r *queueRecorder) events() []string {
+	events := make([]string, len(r.sent))
+	for n, s := range r.sent {
+		events[n] = s.event
+	}
+	return events
+}
+
+func TestNotifyQueue(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	t.Run("coalescing", func(t *testing.T) {
+		r := &queueRecorder{}
+		q := r.queue(100*time.Millisecond, time.Second)
+		q.add(notification{event: hooks.SyncStarted}, at(0))
+		q.add(notification{event: hooks.SyncFinished, items: []string{"a"}}, at(10))
+		q.add(notification{event: hooks.SyncStarted}, at(20))
+		q.add(notification{event: hooks.SyncFinished, items: []string{"b"}}, at(30))
+		q.add(notification{event: hooks.Error}, at(40))
+		q.flush(at(100))
+		require.Empty(t, r.sent)
+		next, ok := q.next()
+		require.True(t, ok)
+		require.Equal(t, at(130), next)
+		q.flush(at(140))
+		require.Equal(t, []string{hooks.SyncFinished, hooks.Error}, r.events())
+		require.Equal(t, []string{"a", "b"}, r.sent[0].items)
+	})
+	t.Run("items are not shared", func(t *testing.T) {
+		r := &queueRecorder{}
+		q := r.queue(100*time.Millisecond, time.Second)
+		first := make([]string, 1, 4)
+		first[0] = "a"
+		q.add(notification{event: hooks.NewItems, items: first}, at(0))
+		q.add(notification{event: hooks.NewItems, items: []string{"b"}}, at(10))
+		q.add(notification{event: hooks.NewItems, items: []string{"c"}}, at(20))
+		q.flush(at(200))
+		require.Equal(t, []string{"a", "b", "c"}, r.sent[0].items)
+		require.Equal(t, []string{"a"}, first)
+		require.Equal(t, []string{"", "", ""}, first[1:4]) // the backing array of replaced notification is not written
+	})
+	t.Run("rate limit", func(t *testing.T) {
+		r := &queueRecorder{}
+		q := r.queue(0, time.Second)
+		q.add(notification{event: hooks.SyncFinished, items: []string{"a"}}, at(0))
+		q.flush(at(0))
+		for n := 1; n <= 3; n++ {
+			q.add(notification{event: hooks.SyncFinished, items: []string{"b", "c"}}, at(n*100))
+			q.flush(at(n * 100))
+		}
+		require.Len(t, r.sent, 1)
+		require.Empty(t, r.summaries)
+		next, ok := q.next()
+		require.True(t, ok)
+		require.Equal(t, at(1000), next)
+		q.flush(at(1000))
+		require.Equal(t, []string{hooks.SyncFinished + " 3 6"}, r.summaries)
+		_, ok = q.next()
+		require.False(t, ok)
+		q.add(notification{event: hooks.SyncFinished}, at(1100))
+		q.flush(at(1100))
+		require.Len(t, r.sent, 2) // new period
+	})
+	t.Run("loop", func(t *testing.T) {
+		sent := make(chan string, 10)
+		q := newNotifyQueue(func(n notification) { sent <- n.event }, func(string, int, int) {}, 10*time.Millisecond, time.Minute)
+		q.push(notification{event: hooks.DaemonStopped})
+		q.push(notification{event: hooks.DaemonStarted})
+		select {
+		case event := <-sent:
+			require.Equal(t, hooks.DaemonStarted, event)
+		case <-time.After(time.Second):
+			t.Fatal("notification was not sent")
+		}
+		q.close()
+		require.Empty(t, sent)
+	})
+	t.Run("close delivers pending", func(t *testing.T) {
+		sent, summaries := make(chan string, 10), make(chan string, 10)
+		q := newNotifyQueue(func(n notification) { sent <- n.event },
+			func(event string, count, files int) { summaries <- fmt.Sprint(event, " ", count, " ", files) }, time.Hour, time.Hour)
+		q.push(notification{event: hooks.Error})
+		q.push(notification{event: hooks.NewItems, items: []string{"a"}})
+		q.close()
+		require.Equal(t, hooks.Error, <-sent)
+		require.Equal(t, hooks.NewItems, <-sent)
+		require.Empty(t, summaries)
+		q = newNotifyQueue(func(n notification) { sent <- n.event },
+			func(event string, count, files int) { summaries <- fmt.Sprint(event, " ", count, " ", files) }, 0, time.Hour)
+		q.push(notification{event: hooks.NewItems, items: []string{"a"}})
+		require.Equal(t, hooks.NewItems, <-sent) // the rate limit period is started
+		q.push(notification{event: hooks.NewItems, items: []string{"b", "c"}})
+		q.close()
+		require.Equal(t, hooks.NewItems+" 1 2", <-summaries)
+		require.Empty(t, sent)
+	})
+	t.Run("slow server", func(t *testing.T) {
+		release := make(chan struct{})
+		q := newNotifyQueue(func(notification) { <-release }, func(string, int, int) {}, 0, 0)
+		pushed := make(chan struct{})
+		go func() {
+			defer close(pushed)
+			for range 100 {
+				q.push(notification{event: hooks.Error})
+			}
+		}()
+		select {
+		case <-pushed:
+		case <-time.After(time.Second):
+			t.Fatal("push is blocked by the notification server")
+		}
+		close(release)
+		q.close()
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/slytomcat/yd-go/tools"
+	"github.com/slytomcat/yd-go/ydisk"
+	"golang.org/x/text/message"
+)
+
+// barModes are the supported status bar output modes
+var barModes = map[string]bool{
+	"waybar": true, // one JSON object per line (waybar custom module with return-type json)
+	"i3bar":  true, // i3bar protocol (also swaybar and i3blocks with JSON format)
+	"text":   true, // plain text line (polybar, i3blocks, etc.)
+}
+
+// barBlock is the status bar block content
+type barBlock struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+	Alt        string `json:"alt"`
+}
+
+// i3barBlock is the block in the i3bar protocol
+type i3barBlock struct {
+	Name      string `json:"name"`
+	FullText  string `json:"full_text"`
+	ShortText string `json:"short_text"`
+}
+
+// statusBar writes the status bar lines in the selected protocol
+type statusBar struct {
+	mode string                                 // output mode
+	out  io.Writer                              // output writer
+	msg  func(message.Reference, ...any) string // localization printer func
+	eta  etaEstimator                           // remaining synchronization time estimator
+}
+
+// start writes the protocol header
+func (b *statusBar) start() {
+	if b.mode == "i3bar" {
+		fmt.Fprint(b.out, "{\"version\":1,\"click_events\":true}\n[\n")
+	}
+}
+
+// block makes the block content from daemon values
+func (b *statusBar) block(yds *ydisk.YDvals) barBlock {
+	status, _ := ydisk.ParseStatus(yds.Stat, yds.Err)
+	blk := barBlock{
+		Text:       b.msg(yds.Stat),
+		Class:      iconStatus(status),
+		Percentage: yds.UsedPercent(),
+		Alt:        yds.Stat,
+	}
+	if p := yds.Progress(); p >= 0 {
+		blk.Text = fmt.Sprintf("%s %d%%", blk.Text, p)
+		blk.Percentage = p
+	}
+	if yds.Err != "" && status != ydisk.StatusAuth {
+		blk.Class = "error"
+	}
+	if blk.Percentage < 0 {
+		blk.Percentage = 0
+	}
+	blk.Tooltip = strings.Join(statusSummary(b.msg, yds, b.eta.update(yds.Prog, time.Now())), "\n")
+	return blk
+}
+
+// write writes the line with status to the output
+func (b *statusBar) write(yds *ydisk.YDvals) {
+	blk := b.block(yds)
+	switch b.mode {
+	case "waybar":
+		data, _ := json.Marshal(blk)
+		fmt.Fprintf(b.out, "%s\n", data)
+	case "i3bar":
+		data, _ := json.Marshal([]i3barBlock{{Name: appName, FullText: blk.Text, ShortText: blk.Text}})
+		fmt.Fprintf(b.out, "%s,\n", data)
+	default:
+		fmt.Fprintln(b.out, blk.Text)
+	}
+}
+
+// parseClick returns the mouse button number from the click event line. The line can be the JSON object
+// of i3bar protocol (optionally prefixed by '[' or ',') or just the button number.
+func parseClick(line string) (int, bool) {
+	line = strings.TrimLeft(strings.TrimSpace(line), "[,")
+	if line == "" {
+		return 0, false
+	}
+	if line[0] == '{' {
+		var click struct {
+			Button int `json:"button"`
+		}
+		if err := json.Unmarshal([]byte(line), &click); err != nil || click.Button == 0 {
+			return 0, false
+		}
+		return click.Button, true
+	}
+	button, err := strconv.Atoi(line)
+	return button, err == nil
+}
+
+// readClicks reads the click events from r and sends the button numbers to clicks until EOF
+func readClicks(r io.Reader, clicks chan<- int) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if button, ok := parseClick(scanner.Text()); ok {
+			clicks <- button
+		}
+	}
+}
+
+// handleClick performs the menu action for the mouse button: left - open synchronized folder,
+// middle - open Yandex.Disk in browser, right - start/stop the daemon.
+func (i *indicator) handleClick(button int) {
+	i.log.Debug("click", "button", button)
+	switch button {
+	case 1:
+		i.openPath(i.yd.Path)
+	case 2:
+		i.openPath(ydURL)
+	case 3:
+		i.toggleDaemon()
+	}
+}
+
+// runStatusBar runs the indicator in the status bar output mode: it writes one status line per daemon
+// status change to stdout and handles the click events from stdin instead of tray icon and menu.
+func runStatusBar(params tools.Params, inst *tools.Instance) {
+	i := newIndicator(params)
+	defer i.close()
+	canceled := make(chan os.Signal, 1)
+	signal.Notify(canceled, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
+	bar := &statusBar{mode: params.StatusBar, out: os.Stdout, msg: i.msg}
+	bar.start()
+	clicks := make(chan int)
+	go readClicks(os.Stdin, clicks)
+	i.log.Debug("status_bar", "status", "started", "mode", params.StatusBar)
+	defer i.log.Debug("status_bar", "status", "exited")
+	for {
+		select {
+		case yds := <-i.yd.Changes:
+			bar.write(&yds)
+			i.stat, i.status = yds.Stat, statusLine(i.msg, &yds)
+			i.reportStatus()
+			yds.Stat, yds.Prev = index2Busy(yds.Stat), index2Busy(yds.Prev)
+			i.handleHooks(statusEvent(&yds), &yds, i.yd.Path)
+		case button := <-clicks:
+			i.handleClick(button)
+		case cmd := <-inst.Commands:
+			i.handleCommand(cmd)
+		case fields := <-i.cfgChanges:
+			i.applyConfig(fields)
+		case <-i.heartbeat():
+			_ = i.sd.Notify("WATCHDOG=1")
+		case sig := <-canceled:
+			i.log.Warn("exit", "signal", sig)
+			return
+		}
+	}
+}
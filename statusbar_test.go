@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/slytomcat/yd-go/ydisk"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+func TestStatusBarWrite(t *testing.T) {
+	yds := &ydisk.YDvals{Stat: "busy", Prog: "65.34 MB/ 139.38 MB (46 %)", Used: "10 GB", Total: "40 GB", Free: "30 GB", Trash: "0 B"}
+	out := &bytes.Buffer{}
+	b := &statusBar{mode: "waybar", out: out, msg: message.NewPrinter(language.English).Sprintf}
+	t.Run("waybar", func(t *testing.T) {
+		b.start()
+		b.write(yds)
+		require.Equal(t, `{"text":"busy 46%","tooltip":"Status: busy 65.34 MB/ 139.38 MB (46 %)\nUsed: 10 GB/40 GB\nFree: 30 GB Trash: 0 B","class":"busy","percentage":46,"alt":"busy"}`+"\n", out.String())
+	})
+	t.Run("i3bar", func(t *testing.T) {
+		out.Reset()
+		b.mode = "i3bar"
+		b.start()
+		b.write(&ydisk.YDvals{Stat: "none"})
+		require.Equal(t, "{\"version\":1,\"click_events\":true}\n[\n[{\"name\":\"yd-go\",\"full_text\":\"none\",\"short_text\":\"none\"}],\n", out.String())
+	})
+	t.Run("text", func(t *testing.T) {
+		out.Reset()
+		b.mode = "text"
+		b.start()
+		b.write(&ydisk.YDvals{Stat: "idle"})
+		require.Equal(t, "idle\n", out.String())
+	})
+	t.Run("block", func(t *testing.T) {
+		blk := b.block(&ydisk.YDvals{Stat: "idle", Err: "access error", ErrP: "file", Used: "10 GB", Total: "40 GB"})
+		require.Equal(t, "error", blk.Class)
+		require.Equal(t, 25, blk.Percentage)
+		require.Contains(t, blk.Tooltip, "access error file")
+		blk = b.block(&ydisk.YDvals{Stat: "none"})
+		require.Equal(t, "paused", blk.Class)
+		require.Equal(t, 0, blk.Percentage)
+		blk = b.block(&ydisk.YDvals{Stat: "no internet access"})
+		require.Equal(t, "offline", blk.Class)
+		blk = b.block(&ydisk.YDvals{Stat: "error", Err: "authorization error"})
+		require.Equal(t, "auth", blk.Class)
+		blk = b.block(&ydisk.YDvals{Stat: "rebooting"})
+		require.Equal(t, "unknown", blk.Class)
+	})
+}
+
+func TestParseClick(t *testing.T) {
+	tests := []struct {
+		line   string
+		button int
+		ok     bool
+	}{
+		{"[", 0, false},
+		{`{"name":"yd-go","button":1,"x":10}`, 1, true},
+		{`,{"name":"yd-go","button":3}`, 3, true},
+		{`[{"name":"yd-go","button":2}`, 2, true},
+		{"2", 2, true},
+		{"", 0, false},
+		{"{bad json", 0, false},
+		{"text", 0, false},
+	}
+	for _, tc := range tests {
+		button, ok := parseClick(tc.line)
+		require.Equal(t, tc.ok, ok, tc.line)
+		require.Equal(t, tc.button, button, tc.line)
+	}
+}
+
+func TestReadClicks(t *testing.T) {
+	clicks := make(chan int, 5)
+	readClicks(strings.NewReader("[\n{\"button\":1}\n,{\"button\":3}\n"), clicks)
+	require.Equal(t, 1, <-clicks)
+	require.Equal(t, 3, <-clicks)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/slytomcat/yd-go/tools"
+	"github.com/slytomcat/yd-go/ydisk"
+	"golang.org/x/text/message"
+)
+
+// etaSmoothing is the weight of the last speed sample in the smoothed synchronization speed
+const etaSmoothing = 0.3
+
+// statusLine returns the localized status line: status, progress and error with error path
+func statusLine(msg func(message.Reference, ...any) string, yds *ydisk.YDvals) string {
+	return joinNonEmpty(msg(yds.Stat), yds.Prog, yds.Err, tools.MakeTitle(yds.ErrP, 30))
+}
+
+// sizeLines returns the localized lines with used/total and free/trash sizes
+func sizeLines(msg func(message.Reference, ...any) string, yds *ydisk.YDvals) (used, free string) {
+	return msg("Used: %s/%s", yds.Used, yds.Total), msg("Free: %s Trash: %s", yds.Free, yds.Trash)
+}
+
+// statusSummary returns the localized lines of status summary: status with progress, remaining time of
+// synchronization (when eta is positive), used and free sizes, the latest error and the last synchronized file.
+func statusSummary(msg func(message.Reference, ...any) string, yds *ydisk.YDvals, eta time.Duration) []string {
+	lines := []string{msg("Status: %s", joinNonEmpty(msg(yds.Stat), yds.Prog))}
+	if eta > 0 {
+		lines = append(lines, msg("Time left: %s", eta.String()))
+	}
+	if yds.Total != "" {
+		used, free := sizeLines(msg, yds)
+		lines = append(lines, used, free)
+	}
+	if yds.Err != "" {
+		lines = append(lines, msg("Error: %s", joinNonEmpty(yds.Err, tools.MakeTitle(yds.ErrP, 30))))
+	}
+	if len(yds.Last) > 0 {
+		lines = append(lines, msg("Last synchronized: %s", tools.MakeTitle(yds.Last[0], 40)))
+	}
+	return lines
+}
+
+// tooltip returns the tray icon tooltip: application title and status summary
+func tooltip(msg func(message.Reference, ...any) string, yds *ydisk.YDvals, eta time.Duration) string {
+	return strings.Join(append([]string{msg(appTitle)}, statusSummary(msg, yds, eta)...), "\n")
+}
+
+// etaEstimator estimates the remaining synchronization time by the smoothed synchronization speed
+type etaEstimator struct {
+	total int64     // total size of current synchronization in bytes
+	done  int64     // synchronized size at the last speed sample in bytes
+	at    time.Time // time of the last speed sample
+	rate  float64   // smoothed speed in bytes per second, 0 when it is not known yet
+}
+
+// parseProgress returns the synchronized and total sizes from progress value like "65.34 MB/ 139.38 MB (46 %)"
+func parseProgress(prog string) (done, total int64, ok bool) {
+	sizes, _, _ := strings.Cut(prog, "(")
+	d, t, found := strings.Cut(sizes, "/")
+	if !found {
+		return 0, 0, false
+	}
+	if done, ok = ydisk.ParseSize(strings.TrimSpace(d)); !ok {
+		return 0, 0, false
+	}
+	if total, ok = ydisk.ParseSize(strings.TrimSpace(t)); !ok {
+		return 0, 0, false
+	}
+	return done, total, true
+}
+
+// update adds the progress sample and returns the estimated remaining time. It returns 0 when the remaining time
+// can't be estimated: there is no progress or the speed is not known yet. The estimation restarts when the next
+// synchronization begins (the total size is changed or the synchronized size is decreased).
+func (e *etaEstimator) update(prog string, now time.Time) time.Duration {
+	done, total, ok := parseProgress(prog)
+	switch {
+	case !ok:
+		*e = etaEstimator{}
+		return 0
+	case e.at.IsZero() || total != e.total || done < e.done:
+		*e = etaEstimator{total: total, done: done, at: now}
+		return 0
+	}
+	if dt := now.Sub(e.at).Seconds(); dt > 0 && done > e.done {
+		rate := float64(done-e.done) / dt
+		if e.rate > 0 {
+			rate = etaSmoothing*rate + (1-etaSmoothing)*e.rate
+		}
+		e.rate, e.done, e.at = rate, done, now
+	}
+	if e.rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(total-done) / e.rate * float64(time.Second)).Round(time.Second)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slytomcat/yd-go/ydisk"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+func TestStatusSummary(t *testing.T) {
+	msg := message.NewPrinter(language.English).Sprintf
+	yds := &ydisk.YDvals{Stat: "busy", Prog: "65.34 MB/ 139.38 MB (46 %)", Used: "10 GB", Total: "40 GB", Free: "30 GB",
+		Trash: "0 B", Err: "access error", ErrP: "docs/file.txt", Last: []string{"docs/new.txt", "docs/old.txt"}}
+	require.Equal(t, []string{
+		"Status: busy 65.34 MB/ 139.38 MB (46 %)",
+		"Time left: 2m30s",
+		"Used: 10 GB/40 GB",
+		"Free: 30 GB Trash: 0 B",
+		"Error: access error docs/file.txt",
+		"Last synchronized: docs/new.txt",
+	}, statusSummary(msg, yds, 150*time.Second))
+	require.Equal(t, []string{"Status: none"}, statusSummary(msg, &ydisk.YDvals{Stat: "none"}, 0))
+	require.Equal(t, appTitle+"\nStatus: idle", tooltip(msg, &ydisk.YDvals{Stat: "idle"}, 0))
+	require.Equal(t, "busy 65.34 MB/ 139.38 MB (46 %) access error docs/file.txt", statusLine(msg, yds))
+}
+
+func TestParseProgress(t *testing.T) {
+	done, total, ok := parseProgress("1.5 MB/ 2 GB (0 %)")
+	require.True(t, ok)
+	require.Equal(t, int64(3<<19), done)
+	require.Equal(t, int64(2<<30), total)
+	_, _, ok = parseProgress("")
+	require.False(t, ok)
+	_, _, ok = parseProgress("some MB/ 1 MB (1 %)")
+	require.False(t, ok)
+}
+
+func TestETAEstimator(t *testing.T) {
+	start := time.Now()
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	var e etaEstimator
+	require.Zero(t, e.update("0 MB/ 100 MB (0 %)", at(0))) // speed is not known yet
+	require.Zero(t, e.update("0 MB/ 100 MB (0 %)", at(1))) // no progress
+	require.Equal(t, 8*time.Second, e.update("20 MB/ 100 MB (20 %)", at(2)))
+	// smoothed speed: 0.3 * 20 MB/s + 0.7 * 10 MB/s = 13 MB/s
+	require.Equal(t, 5*time.Second, e.update("40 MB/ 100 MB (40 %)", at(3)))
+	require.Zero(t, e.update("1 MB/ 50 MB (2 %)", at(4))) // next synchronization
+	require.Zero(t, e.update("", at(5)))
+	require.Equal(t, etaEstimator{}, e)
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+)
+
+// Busy icon animation defaults and limits
+const (
+	DefaultFrameRate = 3  // default busy icon animation frames per second
+	MaxFrameRate     = 30 // maximum busy icon animation frames per second
+)
+
+// AnimationConfig is the busy icon animation configuration
+type AnimationConfig struct {
+	FrameRate     int    `json:",omitempty"` // animation frames per second (default 3)
+	Frames        int    `json:",omitempty"` // number of used animation frames of the icon theme (default 0 - all frames)
+	ReducedMotion string `json:",omitempty"` // "auto" (default) - follow the desktop setting, "on" - static busy icon, "off" - animated busy icon
+}
+
+// validate checks the animation configuration values. The path is the JSON path of animation configuration.
+func (ac AnimationConfig) validate(path string) ValidationError {
+	var errs ValidationError
+	if ac.FrameRate < 0 || ac.FrameRate > MaxFrameRate {
+		errs.add(joinPath(path, "FrameRate"), fmt.Errorf("wrong frame rate: %d (should be from 1 to %d)", ac.FrameRate, MaxFrameRate))
+	}
+	if ac.Frames < 0 {
+		errs.add(joinPath(path, "Frames"), fmt.Errorf("negative number of frames"))
+	}
+	switch ac.ReducedMotion {
+	case "", "auto", "on", "off":
+	default:
+		errs.add(joinPath(path, "ReducedMotion"), fmt.Errorf("wrong reduced motion mode: '%s' (should be 'auto', 'on' or 'off')", ac.ReducedMotion))
+	}
+	return errs
+}
+
+// Interval returns the interval between animation frames
+func (ac AnimationConfig) Interval() time.Duration {
+	rate := ac.FrameRate
+	if rate == 0 {
+		rate = DefaultFrameRate
+	}
+	return time.Second / time.Duration(rate)
+}
+
+// Reduced returns true when the busy icon has to be static. The desktop is the reduced motion setting of desktop
+// that is used in "auto" mode.
+func (ac AnimationConfig) Reduced(desktop bool) bool {
+	switch ac.ReducedMotion {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return desktop
+}
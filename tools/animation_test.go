@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimationConfig(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		require.Empty(t, AnimationConfig{}.validate("Animation"))
+		require.Empty(t, AnimationConfig{FrameRate: 30, Frames: 1, ReducedMotion: "on"}.validate("Animation"))
+		errs := AnimationConfig{FrameRate: 31, Frames: -1, ReducedMotion: "sometimes"}.validate("Animation")
+		require.EqualError(t, errs.err(), `Animation.FrameRate: wrong frame rate: 31 (should be from 1 to 30)
+Animation.Frames: negative number of frames
+Animation.ReducedMotion: wrong reduced motion mode: 'sometimes' (should be 'auto', 'on' or 'off')`)
+	})
+	t.Run("interval", func(t *testing.T) {
+		require.Equal(t, time.Second/3, AnimationConfig{}.Interval())
+		require.Equal(t, 100*time.Millisecond, AnimationConfig{FrameRate: 10}.Interval())
+	})
+	t.Run("reduced", func(t *testing.T) {
+		require.True(t, AnimationConfig{}.Reduced(true))
+		require.False(t, AnimationConfig{ReducedMotion: "auto"}.Reduced(false))
+		require.True(t, AnimationConfig{ReducedMotion: "on"}.Reduced(false))
+		require.False(t, AnimationConfig{ReducedMotion: "off"}.Reduced(true))
+	})
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Tray icon click actions
+const (
+	ClickMenu   = "menu"   // show the menu, the click is handled by the tray host (default)
+	ClickOpen   = "open"   // open the synchronized folder
+	ClickToggle = "toggle" // start the stopped daemon or stop the running one
+	ClickOutput = "output" // show the daemon output
+	ClickSite   = "site"   // open the Yandex.Disk web UI
+)
+
+// clickActions are the valid click actions
+var clickActions = []string{ClickMenu, ClickOpen, ClickToggle, ClickOutput, ClickSite}
+
+// ClickConfig is the tray icon click actions configuration. The secondary click handler also receives the right
+// click, and the menu can't be opened when both clicks have handlers, so at least one click has to show the menu.
+// The change between "menu" and other actions is applied after restart as the tray icon is registered with
+// the click handlers.
+type ClickConfig struct {
+	Primary   string `json:",omitempty"` // action on the primary activation (left click), default "menu"
+	Secondary string `json:",omitempty"` // action on the secondary activation (middle and right click), default "menu"
+}
+
+// validate checks the click actions. The path is the JSON path of click actions configuration.
+func (cc ClickConfig) validate(path string) ValidationError {
+	var errs ValidationError
+	for _, f := range []struct{ name, action string }{{"Primary", cc.Primary}, {"Secondary", cc.Secondary}} {
+		if !validClickAction(f.action) {
+			errs.add(joinPath(path, f.name), fmt.Errorf("wrong click action: '%s' (should be '%s')", f.action,
+				strings.Join(clickActions, "', '")))
+		}
+	}
+	if len(errs) == 0 && ClickAction(cc.Primary) != ClickMenu && ClickAction(cc.Secondary) != ClickMenu {
+		errs.add(joinPath(path, "Secondary"), fmt.Errorf("secondary click action should be 'menu' when primary one is '%s' (one click has to show the menu)", cc.Primary))
+	}
+	return errs
+}
+
+// validClickAction returns true for known click action or empty action that means the default one
+func validClickAction(action string) bool {
+	return action == "" || slices.Contains(clickActions, action)
+}
+
+// ClickAction returns the action with the default one for empty action
+func ClickAction(action string) string {
+	if action == "" {
+		return ClickMenu
+	}
+	return action
+}
+
+// GetClick returns the tray icon click actions configuration
+func (c *Config) GetClick() ClickConfig {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Click
+}
@@ -0,0 +1,24 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickConfig(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		require.Empty(t, ClickConfig{}.validate("Click"))
+		require.Empty(t, ClickConfig{Primary: "open"}.validate("Click"))
+		require.Empty(t, ClickConfig{Primary: "menu", Secondary: "toggle"}.validate("Click"))
+		require.EqualError(t, ClickConfig{Primary: "open", Secondary: "toggle"}.validate("Click").err(),
+			"Click.Secondary: secondary click action should be 'menu' when primary one is 'open' (one click has to show the menu)")
+		errs := ClickConfig{Primary: "Open", Secondary: "exit"}.validate("Click")
+		require.EqualError(t, errs.err(), `Click.Primary: wrong click action: 'Open' (should be 'menu', 'open', 'toggle', 'output', 'site')
+Click.Secondary: wrong click action: 'exit' (should be 'menu', 'open', 'toggle', 'output', 'site')`)
+	})
+	t.Run("action", func(t *testing.T) {
+		require.Equal(t, ClickMenu, ClickAction(""))
+		require.Equal(t, ClickSite, ClickAction("site"))
+	})
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// NoClipboard is the name of clipboard that is used when there is no clipboard tool
+const NoClipboard = "none"
+
+// Clipboard copies the text to the desktop clipboard
+type Clipboard interface {
+	Copy(text string) error // copies the text to the clipboard
+	Name() string           // name of clipboard backend
+}
+
+// commandClipboard copies the text by the clipboard tool that reads the text from its standard input
+type commandClipboard struct {
+	cmd  string   // tool executable
+	args []string // tool arguments that select the clipboard
+}
+
+// clipboardTools are the supported clipboard tools in order of preference and the display variables that are
+// required for them
+var clipboardTools = []struct {
+	display   string
+	clipboard commandClipboard
+}{
+	{"WAYLAND_DISPLAY", commandClipboard{cmd: "wl-copy"}},
+	{"DISPLAY", commandClipboard{cmd: "xclip", args: []string{"-selection", "clipboard"}}},
+	{"DISPLAY", commandClipboard{cmd: "xsel", args: []string{"--clipboard", "--input"}}},
+}
+
+// Copy implements Clipboard. The tool output is not read as the tool can stay in background to serve the clipboard.
+func (c commandClipboard) Copy(text string) error {
+	cmd := exec.Command(c.cmd, c.args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Name implements Clipboard
+func (c commandClipboard) Name() string {
+	return c.cmd
+}
+
+// NoopClipboard is the clipboard that only keeps the last copied text. It is used when there is no clipboard tool
+// and in tests.
+type NoopClipboard struct {
+	lock sync.Mutex
+	text string
+}
+
+// Copy implements Clipboard
+func (c *NoopClipboard) Copy(text string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.text = text
+	return nil
+}
+
+// Name implements Clipboard
+func (c *NoopClipboard) Name() string {
+	return NoClipboard
+}
+
+// Text returns the last copied text
+func (c *NoopClipboard) Text() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.text
+}
+
+// NewClipboard returns the clipboard of the first available clipboard tool: wl-copy in Wayland session, xclip or
+// xsel in X session. It returns NoopClipboard when there is no tool for the current session.
+func NewClipboard() Clipboard {
+	for _, t := range clipboardTools {
+		if os.Getenv(t.display) == "" {
+			continue
+		}
+		if _, err := exec.LookPath(t.clipboard.cmd); err == nil {
+			return t.clipboard
+		}
+	}
+	return &NoopClipboard{}
+}
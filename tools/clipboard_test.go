@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTools creates the clipboard tools scripts that write their arguments and the first line of input to the result
+// file. The scripts use only shell built-ins as PATH contains only the fake tools.
+func fakeTools(t *testing.T, result string, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		script := "#!/bin/sh\necho \"" + name + " $@\" > \"" + result + "\"\nIFS= read -r line\nprintf '%s' \"$line\" >> \"" + result + "\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0766))
+	}
+	return dir
+}
+
+func TestNewClipboard(t *testing.T) {
+	result := filepath.Join(t.TempDir(), "result")
+	for _, tc := range []struct {
+		name, wayland, display, want string
+		tools                        []string
+	}{
+		{"wayland", "wayland-0", ":0", "wl-copy", []string{"wl-copy", "xclip", "xsel"}},
+		{"xwayland", "wayland-0", ":0", "xclip", []string{"xclip", "xsel"}},
+		{"x11", "", ":0", "xclip", []string{"wl-copy", "xclip", "xsel"}},
+		{"xsel", "", ":0", "xsel", []string{"wl-copy", "xsel"}},
+		{"no tools", "wayland-0", ":0", NoClipboard, nil},
+		{"no session", "", "", NoClipboard, []string{"wl-copy", "xclip", "xsel"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("PATH", fakeTools(t, result, tc.tools...))
+			t.Setenv("WAYLAND_DISPLAY", tc.wayland)
+			t.Setenv("DISPLAY", tc.display)
+			require.Equal(t, tc.want, NewClipboard().Name())
+		})
+	}
+}
+
+func TestClipboardCopy(t *testing.T) {
+	t.Run("command", func(t *testing.T) {
+		result := filepath.Join(t.TempDir(), "result")
+		t.Setenv("PATH", fakeTools(t, result, "xclip"))
+		t.Setenv("WAYLAND_DISPLAY", "")
+		t.Setenv("DISPLAY", ":0")
+		require.NoError(t, NewClipboard().Copy("/home/user/Yandex.Disk/a b.txt"))
+		data, err := os.ReadFile(result)
+		require.NoError(t, err)
+		require.Equal(t, "xclip -selection clipboard\n/home/user/Yandex.Disk/a b.txt", string(data))
+	})
+	t.Run("command error", func(t *testing.T) {
+		require.Error(t, commandClipboard{cmd: filepath.Join(t.TempDir(), "none")}.Copy("text"))
+	})
+	t.Run("noop", func(t *testing.T) {
+		cb := &NoopClipboard{}
+		require.NoError(t, cb.Copy("link"))
+		require.Equal(t, "link", cb.Text())
+	})
+}
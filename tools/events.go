@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/slytomcat/yd-go/hooks"
+)
+
+// QuotaWarning is the event of the disk space usage exceeding the warning level. It is used only for notifications.
+const QuotaWarning = "quota_warning"
+
+// NotifyEvents are the events that can be notified. The values are the default notification settings.
+var NotifyEvents = map[string]bool{
+	hooks.DaemonStarted: true,
+	hooks.DaemonStopped: true,
+	hooks.SyncStarted:   true,
+	hooks.SyncFinished:  true,
+	hooks.Error:         true,
+	QuotaWarning:        true,
+	hooks.NewItems:      false,
+}
+
+// validateNotifyEvents checks the event names in NotifyEvents field
+func (c *Config) validateNotifyEvents(errs *ValidationError) {
+	for _, event := range slices.Sorted(maps.Keys(c.NotifyEvents)) {
+		if _, ok := NotifyEvents[event]; !ok {
+			errs.add(joinPath("NotifyEvents", event), fmt.Errorf("unknown event"))
+		}
+	}
+}
+
+// GetNotifyEvent returns true when the notifications about the event are switched on. It takes into account
+// the Notifications field that switches all notifications.
+func (c *Config) GetNotifyEvent(event string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.Notifications {
+		return false
+	}
+	if on, ok := c.NotifyEvents[event]; ok {
+		return on
+	}
+	return NotifyEvents[event]
+}
+
+// SetNotifyEvent switches the notifications about the event and triggers delayed saving of configuration to the disk
+func (c *Config) SetNotifyEvent(event string, on bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// copy the map as the previous one may be shared with the configuration read from the file
+	events := maps.Clone(c.NotifyEvents)
+	if events == nil {
+		events = make(map[string]bool)
+	}
+	events[event] = on
+	c.NotifyEvents = events
+	c.touch("NotifyEvents")
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/slytomcat/yd-go/hooks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyEvents(t *testing.T) {
+	logger := SetupLogger(false, os.Stdout)
+	t.Run("defaults and switches", func(t *testing.T) {
+		content := `{"NotifyEvents":{"sync_started":false,"new_items":true}}`
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		require.False(t, cfg.GetNotifyEvent(hooks.SyncStarted))
+		require.True(t, cfg.GetNotifyEvent(hooks.NewItems))
+		require.True(t, cfg.GetNotifyEvent(hooks.Error)) // default value
+		cfg.SetNotifyEvent(hooks.Error, false)
+		require.False(t, cfg.GetNotifyEvent(hooks.Error))
+		cfg.SetNotifications(false)
+		require.False(t, cfg.GetNotifyEvent(hooks.NewItems)) // all notifications are switched off
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Notifications":false,"NotifyEvents":{"error":false,"new_items":true,"sync_started":false}`)
+	})
+	t.Run("unknown event", func(t *testing.T) {
+		content := `{"NotifyEvents":{"sync_started":false,"reboot":true}}`
+		testFile := makeTempCfgFile(t, &content)
+		_, err := NewConfig(testFile, time.Hour, logger)
+		require.ErrorContains(t, err, "NotifyEvents.reboot: unknown event")
+	})
+}
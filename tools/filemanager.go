@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	fileManagerDest    = "org.freedesktop.FileManager1"
+	fileManagerPath    = "/org/freedesktop/FileManager1"
+	fileManagerTimeout = 10 * time.Second // the file manager can be started by D-BUS activation
+)
+
+// showItems calls the ShowItems method of file manager D-BUS interface. It is replaced in tests.
+var showItems = func(uris []string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), fileManagerTimeout)
+	defer cancel()
+	return conn.Object(fileManagerDest, fileManagerPath).CallWithContext(ctx, fileManagerDest+".ShowItems", 0, uris, "").Err
+}
+
+// fileURI returns the file URI of absolute path
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// ShowInFolder shows the file or folder highlighted in its parent folder by the file manager via
+// org.freedesktop.FileManager1 D-BUS interface. It opens the parent folder via xdg-open when the file manager
+// interface is not available.
+func ShowInFolder(path string) error {
+	err := showItems([]string{fileURI(path)})
+	if err == nil {
+		return nil
+	}
+	if openErr := XdgOpen(filepath.Dir(path)); openErr != nil {
+		return errors.Join(fmt.Errorf("file manager error: %w", err), openErr)
+	}
+	return nil
+}
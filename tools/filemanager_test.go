@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowInFolder(t *testing.T) {
+	var shown []string
+	origShowItems := showItems
+	showItems = func(uris []string) error {
+		shown = append(shown, uris...)
+		return nil
+	}
+	defer func() { showItems, xdgOpenCmd = origShowItems, "xdg-open" }()
+	t.Run("file manager", func(t *testing.T) {
+		require.NoError(t, ShowInFolder("/home/user/Yandex.Disk/My docs/#1.txt"))
+		require.Equal(t, []string{"file:///home/user/Yandex.Disk/My%20docs/%231.txt"}, shown)
+	})
+	t.Run("fallback", func(t *testing.T) {
+		showItems = func([]string) error { return errors.New("no file manager") }
+		resultPath := filepath.Join(t.TempDir(), "xdg-open-result.txt")
+		xdgOpenCmd = filepath.Join(t.TempDir(), "xdg-open-mock.sh")
+		require.NoError(t, os.WriteFile(xdgOpenCmd, []byte("#!/bin/sh\necho \"$@\" > \""+resultPath+"\"\n"), 0766))
+		require.NoError(t, ShowInFolder("/home/user/Yandex.Disk/docs/a.txt"))
+		require.Eventually(t, func() bool {
+			data, _ := os.ReadFile(resultPath)
+			return string(data) == "/home/user/Yandex.Disk/docs\n"
+		}, time.Second, 10*time.Millisecond)
+	})
+	t.Run("fallback error", func(t *testing.T) {
+		xdgOpenCmd = filepath.Join(t.TempDir(), "none")
+		err := ShowInFolder("/home/user/Yandex.Disk/docs/a.txt")
+		require.ErrorContains(t, err, "file manager error: no file manager")
+	})
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrAlreadyRunning is returned by NewInstance when another instance with the same configuration is already running
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+// Instance is the single instance guard. It holds the lock on the runtime file and listens the control socket
+// for commands sent by the other launches with the same configuration file.
+type Instance struct {
+	Commands chan string  // channel for receiving the commands from other launches
+	lock     *os.File     // locked runtime file
+	listener net.Listener // control socket listener
+	sockPath string       // path to control socket
+}
+
+// instanceFiles returns the paths to lock file and control socket which are unique for the configuration file path.
+func instanceFiles(dir, cfgPath string) (string, string) {
+	if abs, err := filepath.Abs(cfgPath); err == nil {
+		cfgPath = abs
+	}
+	sum := sha1.Sum([]byte(cfgPath))
+	name := filepath.Join(dir, filepath.Base(cfgPath)+"-"+hex.EncodeToString(sum[:4]))
+	return name + ".lock", name + ".sock"
+}
+
+// NewInstance takes the lock for configuration file and starts to listen the control socket.
+// The lock and the socket are created in the dir. It returns ErrAlreadyRunning when the lock is held by another process.
+// Use Close for releasing the lock and the socket.
+func NewInstance(dir, cfgPath string) (*Instance, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("can't create runtime directory: %v", err)
+	}
+	lockPath, sockPath := instanceFiles(dir, cfgPath)
+	lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("can't open lock file: %v", err)
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lock.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrAlreadyRunning
+		}
+		return nil, fmt.Errorf("can't lock file: %v", err)
+	}
+	os.Remove(sockPath) // socket may be left by previous crashed instance, the lock guarantees that it is not in use
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("can't listen control socket: %v", err)
+	}
+	in := &Instance{
+		Commands: make(chan string, 4),
+		lock:     lock,
+		listener: listener,
+		sockPath: sockPath,
+	}
+	go in.serve()
+	return in, nil
+}
+
+// serve accepts connections to control socket until the listener is closed
+func (in *Instance) serve() {
+	for {
+		conn, err := in.listener.Accept()
+		if err != nil {
+			return // listener is closed
+		}
+		go in.handle(conn)
+	}
+}
+
+// handle reads one command from connection, passes it to Commands channel and replies "ok"
+func (in *Instance) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	select {
+	case in.Commands <- strings.TrimSpace(line):
+		_, _ = conn.Write([]byte("ok\n"))
+	case <-time.After(time.Second):
+	}
+}
+
+// Close stops listening of the control socket and releases the lock
+func (in *Instance) Close() {
+	in.listener.Close()
+	os.Remove(in.sockPath)
+	in.lock.Close()
+}
+
+// SendCommand sends the command to the running instance with the same configuration file.
+func SendCommand(dir, cfgPath, command string) error {
+	_, sockPath := instanceFiles(dir, cfgPath)
+	conn, err := net.DialTimeout("unix", sockPath, time.Second)
+	if err != nil {
+		return fmt.Errorf("can't connect to running instance: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err = conn.Write([]byte(command + "\n")); err != nil {
+		return fmt.Errorf("can't send command: %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || reply != "ok\n" {
+		return fmt.Errorf("command is not accepted by running instance")
+	}
+	return nil
+}
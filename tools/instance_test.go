@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstance(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run")
+	cfg := filepath.Join(t.TempDir(), "default.cfg")
+	in, err := NewInstance(dir, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, in)
+	t.Run("second instance", func(t *testing.T) {
+		in2, err := NewInstance(dir, cfg)
+		require.ErrorIs(t, err, ErrAlreadyRunning)
+		require.Nil(t, in2)
+	})
+	t.Run("other config", func(t *testing.T) {
+		in2, err := NewInstance(dir, cfg+"_other")
+		require.NoError(t, err)
+		in2.Close()
+	})
+	t.Run("send command", func(t *testing.T) {
+		require.NoError(t, SendCommand(dir, cfg, "start"))
+		require.Equal(t, "start", <-in.Commands)
+	})
+	in.Close()
+	t.Run("send after close", func(t *testing.T) {
+		require.Error(t, SendCommand(dir, cfg, "stop"))
+	})
+	t.Run("lock after close", func(t *testing.T) {
+		in, err := NewInstance(dir, cfg)
+		require.NoError(t, err)
+		in.Close()
+	})
+	t.Run("bad dir", func(t *testing.T) {
+		_, err := NewInstance("/dev/null/dir", cfg)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+package tools
+
+import "fmt"
+
+// MaxLastItems is the maximum number of last synchronized items that the daemon reports
+const MaxLastItems = 10
+
+// LastConfig is the last synchronized items menu configuration
+type LastConfig struct {
+	Items int  `json:",omitempty"` // number of shown items (default 0 - all items reported by daemon)
+	Group bool `json:",omitempty"` // group the items by folders
+}
+
+// validate checks the last synchronized items menu configuration. The path is the JSON path of this configuration.
+func (lc LastConfig) validate(path string) ValidationError {
+	var errs ValidationError
+	if lc.Items < 0 || lc.Items > MaxLastItems {
+		errs.add(joinPath(path, "Items"), fmt.Errorf("wrong number of items: %d (should be from 1 to %d)", lc.Items, MaxLastItems))
+	}
+	return errs
+}
+
+// Length returns the number of shown items
+func (lc LastConfig) Length() int {
+	if lc.Items == 0 {
+		return MaxLastItems
+	}
+	return lc.Items
+}
+
+// GetLast returns the last synchronized items menu configuration
+func (c *Config) GetLast() LastConfig {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Last
+}
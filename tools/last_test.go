@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastConfig(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		require.Empty(t, LastConfig{}.validate("Last"))
+		require.Empty(t, LastConfig{Items: 10, Group: true}.validate("Last"))
+		require.EqualError(t, LastConfig{Items: 11}.validate("Last").err(), "Last.Items: wrong number of items: 11 (should be from 1 to 10)")
+		require.EqualError(t, LastConfig{Items: -1}.validate("Last").err(), "Last.Items: wrong number of items: -1 (should be from 1 to 10)")
+	})
+	t.Run("length", func(t *testing.T) {
+		require.Equal(t, MaxLastItems, LastConfig{}.Length())
+		require.Equal(t, 3, LastConfig{Items: 3}.Length())
+	})
+}
@@ -0,0 +1,457 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var journalSocket = "/run/systemd/journal/socket"
+
+const (
+	defaultLogMaxSize  = 1024 // default log file size limit in KB
+	defaultLogMaxFiles = 3    // default number of rotated log files
+)
+
+// LogConfig is the logging configuration
+type LogConfig struct {
+	Format   string            `json:",omitempty"` // "text" (default) or "json"
+	Output   string            `json:",omitempty"` // "stdout" (default), "stderr", "file" or "journald"
+	Level    string            `json:",omitempty"` // default level: "debug", "info" (default), "warn" or "error"
+	Levels   map[string]string `json:",omitempty"` // per component levels, components: "ydisk", "notify", "icons", "ui"
+	MaxSize  int               `json:",omitempty"` // log file size limit in KB before rotation (default 1024)
+	MaxFiles int               `json:",omitempty"` // number of kept rotated log files (default 3)
+}
+
+// parseLevel converts the level name into slog.Level
+func parseLevel(name string) (slog.Level, error) {
+	var l slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	err := l.UnmarshalText([]byte(name))
+	return l, err
+}
+
+// Validate checks the logging configuration values. It returns the first found error.
+func (lc LogConfig) Validate() error {
+	if errs := lc.validate(""); len(errs) > 0 {
+		return errs[0].Err
+	}
+	return nil
+}
+
+// validate checks the logging configuration values. The path is the JSON path of logging configuration.
+func (lc LogConfig) validate(path string) ValidationError {
+	var errs ValidationError
+	switch lc.Format {
+	case "", "text", "json":
+	default:
+		errs.add(joinPath(path, "Format"), fmt.Errorf("wrong log format: '%s' (should be 'text' or 'json')", lc.Format))
+	}
+	switch lc.Output {
+	case "", "stdout", "stderr", "file", "journald":
+	default:
+		errs.add(joinPath(path, "Output"), fmt.Errorf("wrong log output: '%s' (should be 'stdout', 'stderr', 'file' or 'journald')", lc.Output))
+	}
+	if _, err := parseLevel(lc.Level); err != nil {
+		errs.add(joinPath(path, "Level"), fmt.Errorf("wrong log level: '%s'", lc.Level))
+	}
+	components := make([]string, 0, len(lc.Levels))
+	for c := range lc.Levels {
+		components = append(components, c)
+	}
+	slices.Sort(components)
+	for _, c := range components {
+		if _, err := parseLevel(lc.Levels[c]); err != nil {
+			errs.add(joinPath(path, "Levels."+c), fmt.Errorf("wrong log level for component '%s': '%s'", c, lc.Levels[c]))
+		}
+	}
+	if lc.MaxSize < 0 {
+		errs.add(joinPath(path, "MaxSize"), fmt.Errorf("negative log file rotation limits"))
+	}
+	if lc.MaxFiles < 0 {
+		errs.add(joinPath(path, "MaxFiles"), fmt.Errorf("negative log file rotation limits"))
+	}
+	return errs
+}
+
+// Logger is the application logger with selectable output, format and per component levels.
+// The component is set by the "component" attribute, e.g. log.With("component", "ydisk").
+// The configuration can be changed at runtime via Configure.
+type Logger struct {
+	*slog.Logger
+	state *logState
+}
+
+// logState is the shared mutable state of all handlers derived from Logger
+type logState struct {
+	lock    sync.RWMutex
+	base    slog.Handler          // current output handler
+	level   slog.Level            // default level
+	levels  map[string]slog.Level // per component levels
+	closer  io.Closer             // current output closer, nil for stdout/stderr
+	appName string                // application name for log file and journal identifier
+	stdout  io.Writer             // writer for "stdout" output
+}
+
+// NewLogger creates the logger according to the configuration. The stdout is used for "stdout" output.
+// Use Close to release the log output.
+func NewLogger(cfg LogConfig, appName string, stdout io.Writer) (*Logger, error) {
+	st := &logState{appName: appName, stdout: stdout}
+	if err := st.configure(cfg); err != nil {
+		return nil, err
+	}
+	return &Logger{
+		Logger: slog.New(&logHandler{state: st}),
+		state:  st,
+	}, nil
+}
+
+// Configure changes the logger configuration. The loggers derived from Logger are affected too.
+func (l *Logger) Configure(cfg LogConfig) error {
+	return l.state.configure(cfg)
+}
+
+// Close closes the log output
+func (l *Logger) Close() {
+	l.state.lock.Lock()
+	defer l.state.lock.Unlock()
+	if l.state.closer != nil {
+		l.state.closer.Close()
+		l.state.closer = nil
+	}
+}
+
+// configure creates the output handler and sets the levels
+func (st *logState) configure(cfg LogConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	level, _ := parseLevel(cfg.Level)
+	levels := make(map[string]slog.Level, len(cfg.Levels))
+	for c, l := range cfg.Levels {
+		levels[c], _ = parseLevel(l)
+	}
+	var (
+		out    io.Writer
+		closer io.Closer
+		base   slog.Handler
+	)
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4} // levels are checked by logHandler
+	switch cfg.Output {
+	case "", "stdout":
+		out = st.stdout
+	case "stderr":
+		out = os.Stderr
+	case "file":
+		maxSize, maxFiles := cfg.MaxSize, cfg.MaxFiles
+		if maxSize == 0 {
+			maxSize = defaultLogMaxSize
+		}
+		if maxFiles == 0 {
+			maxFiles = defaultLogMaxFiles
+		}
+		f, err := newRotatingFile(filepath.Join(StateDir(st.appName), st.appName+".log"), int64(maxSize)<<10, maxFiles)
+		if err != nil {
+			return err
+		}
+		out, closer = f, f
+	case "journald":
+		j, err := newJournalWriter(journalSocket)
+		if err != nil {
+			return err
+		}
+		base, closer = &journalHandler{w: j, ident: st.appName}, j
+	}
+	if base == nil {
+		if cfg.Format == "json" {
+			base = slog.NewJSONHandler(out, opts)
+		} else {
+			base = slog.NewTextHandler(out, opts)
+		}
+	}
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	if st.closer != nil {
+		st.closer.Close()
+	}
+	st.base, st.closer, st.level, st.levels = base, closer, level, levels
+	return nil
+}
+
+// enabled checks the level for component
+func (st *logState) enabled(component string, level slog.Level) bool {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+	if l, ok := st.levels[component]; ok {
+		return level >= l
+	}
+	return level >= st.level
+}
+
+// handlerOp is the attributes or group that have to be applied to the base handler
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+// logHandler checks the per component levels and passes records to current base handler of logState
+type logHandler struct {
+	state     *logState
+	component string
+	ops       []handlerOp
+}
+
+// Enabled implements slog.Handler
+func (h *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.state.enabled(h.component, level)
+}
+
+// Handle implements slog.Handler. It replays the attributes and groups on the current base handler. The lock is
+// held during the write as Configure closes the previous output.
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.state.lock.RLock()
+	defer h.state.lock.RUnlock()
+	base := h.state.base
+	for _, op := range h.ops {
+		if op.group != "" {
+			base = base.WithGroup(op.group)
+		} else {
+			base = base.WithAttrs(op.attrs)
+		}
+	}
+	return base.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler. The "component" attribute sets the component for level checks.
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	n := &logHandler{state: h.state, component: h.component, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{attrs: attrs})}
+	for _, a := range attrs {
+		if a.Key == "component" {
+			n.component = a.Value.String()
+		}
+	}
+	return n
+}
+
+// WithGroup implements slog.Handler
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &logHandler{state: h.state, component: h.component, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{group: name})}
+}
+
+// rotatingFile is the log file writer with rotation by size
+type rotatingFile struct {
+	lock     sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// newRotatingFile opens the log file for appending. The file is rotated when its size exceeds maxSize:
+// path.1 ... path.<maxFiles> are the previous log files.
+func newRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("can't create log directory: %v", err)
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open log file: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("can't open log file: %v", err)
+	}
+	r.file, r.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts the previous log files and opens the new one
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for n := r.maxFiles - 1; n > 0; n-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, n), fmt.Sprintf("%s.%d", r.path, n+1))
+	}
+	os.Rename(r.path, r.path+".1")
+	return r.open()
+}
+
+// Write implements io.Writer
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (r *rotatingFile) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// journalWriter sends the entries to journald via its native protocol socket
+type journalWriter struct {
+	conn *net.UnixConn
+}
+
+func newJournalWriter(socket string) (*journalWriter, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to journald: %v", err)
+	}
+	return &journalWriter{conn: conn}, nil
+}
+
+// Close implements io.Closer
+func (j *journalWriter) Close() error {
+	return j.conn.Close()
+}
+
+// journalHandler is the slog.Handler that writes the records as journal entries with structured fields
+type journalHandler struct {
+	w      *journalWriter
+	ident  string
+	prefix string      // groups prefix for field names
+	attrs  []slog.Attr // attributes with prefixed keys
+}
+
+// Enabled implements slog.Handler (levels are checked by logHandler)
+func (h *journalHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// WithAttrs implements slog.Handler
+func (h *journalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	n := *h
+	n.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], prefixAttrs(h.prefix, attrs)...)
+	return &n
+}
+
+// WithGroup implements slog.Handler
+func (h *journalHandler) WithGroup(name string) slog.Handler {
+	n := *h
+	n.prefix = h.prefix + name + "_"
+	return &n
+}
+
+// prefixAttrs adds the groups prefix to attributes keys and flattens the group attributes
+func prefixAttrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	res := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			res = append(res, prefixAttrs(prefix+a.Key+"_", a.Value.Group())...)
+			continue
+		}
+		res = append(res, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+	}
+	return res
+}
+
+// journalPriority converts slog level to syslog priority
+func journalPriority(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "3"
+	case l >= slog.LevelWarn:
+		return "4"
+	case l >= slog.LevelInfo:
+		return "6"
+	}
+	return "7"
+}
+
+// journalReserved are the journal fields that are set by the handler or have special meaning for journald
+var journalReserved = []string{"MESSAGE", "MESSAGE_ID", "PRIORITY", "ERRNO", "DOCUMENTATION", "TID", "INVOCATION_ID",
+	"USER_INVOCATION_ID"}
+
+// journalField converts the attribute key to the journal field name: upper case letters, digits and underscores,
+// not starting with underscore or digit. The names of reserved fields (including SYSLOG_* and CODE_* ones) are
+// escaped by "F_" prefix to keep the fields that are set by the handler or journald.
+func journalField(key string) string {
+	name := strings.Map(func(r rune) rune {
+		r = unicode.ToUpper(r)
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key)
+	switch {
+	case name == "" || name[0] == '_' || (name[0] >= '0' && name[0] <= '9'):
+		name = "F" + name
+	case slices.Contains(journalReserved, name) || strings.HasPrefix(name, "SYSLOG_") || strings.HasPrefix(name, "CODE_"):
+		name = "F_" + name
+	}
+	return name
+}
+
+// writeJournalField writes the field in the journal native protocol format
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(b, "%s=%s\n", name, value)
+		return
+	}
+	b.WriteString(name)
+	b.WriteByte('\n')
+	_ = binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// Handle implements slog.Handler
+func (h *journalHandler) Handle(_ context.Context, r slog.Record) error {
+	b := &bytes.Buffer{}
+	writeJournalField(b, "MESSAGE", r.Message)
+	writeJournalField(b, "PRIORITY", journalPriority(r.Level))
+	writeJournalField(b, "SYSLOG_IDENTIFIER", h.ident)
+	attrs := h.attrs
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, prefixAttrs(h.prefix, []slog.Attr{a})...)
+		return true
+	})
+	for _, a := range attrs {
+		writeJournalField(b, journalField(a.Key), a.Value.String())
+	}
+	_, err := h.w.conn.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogConfigValidate(t *testing.T) {
+	require.NoError(t, LogConfig{}.Validate())
+	require.NoError(t, LogConfig{Format: "json", Output: "journald", Level: "debug", Levels: map[string]string{"ydisk": "warn"}}.Validate())
+	require.EqualError(t, LogConfig{Format: "xml"}.Validate(), "wrong log format: 'xml' (should be 'text' or 'json')")
+	require.EqualError(t, LogConfig{Output: "syslog"}.Validate(), "wrong log output: 'syslog' (should be 'stdout', 'stderr', 'file' or 'journald')")
+	require.EqualError(t, LogConfig{Level: "verbose"}.Validate(), "wrong log level: 'verbose'")
+	require.EqualError(t, LogConfig{Levels: map[string]string{"ui": "loud"}}.Validate(), "wrong log level for component 'ui': 'loud'")
+	require.Error(t, LogConfig{MaxSize: -1}.Validate())
+}
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l, err := NewLogger(LogConfig{Levels: map[string]string{"ydisk": "debug", "notify": "error"}}, "test", out)
+	require.NoError(t, err)
+	defer l.Close()
+	t.Run("component levels", func(t *testing.T) {
+		out.Reset()
+		l.Debug("default_debug")
+		l.With("component", "ydisk").Debug("ydisk_debug")
+		l.With("component", "notify").Warn("notify_warn")
+		l.With("component", "ui").Info("ui_info", "key", "value")
+		require.NotContains(t, out.String(), "default_debug")
+		require.Contains(t, out.String(), "msg=ydisk_debug component=ydisk")
+		require.NotContains(t, out.String(), "notify_warn")
+		require.Contains(t, out.String(), "msg=ui_info component=ui key=value")
+	})
+	t.Run("icons component", func(t *testing.T) {
+		require.NoError(t, l.Configure(LogConfig{Levels: map[string]string{"icons": "debug"}}))
+		out.Reset()
+		l.With("component", "icons").Debug("theme", "set", "light")
+		l.With("component", "ui").Debug("ui_debug")
+		require.Contains(t, out.String(), "msg=theme component=icons set=light")
+		require.NotContains(t, out.String(), "ui_debug")
+	})
+	t.Run("json format", func(t *testing.T) {
+		out.Reset()
+		derived := l.With("component", "ui").WithGroup("g")
+		require.NoError(t, l.Configure(LogConfig{Format: "json", Level: "debug"}))
+		derived.Debug("json_msg", "key", 1) // derived loggers follow the new configuration
+		rec := map[string]any{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &rec))
+		require.Equal(t, "json_msg", rec["msg"])
+		require.Equal(t, "ui", rec["component"])
+		require.Equal(t, map[string]any{"key": 1.0}, rec["g"])
+	})
+	t.Run("wrong config", func(t *testing.T) {
+		require.Error(t, l.Configure(LogConfig{Format: "xml"}))
+		_, err := NewLogger(LogConfig{Output: "journald"}, "test", out)
+		if NotExists(journalSocket) {
+			require.Error(t, err)
+		}
+	})
+}
+
+// blockingHandler blocks the writes until release is closed
+type blockingHandler struct {
+	slog.Handler
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) Handle(context.Context, slog.Record) error {
+	h.entered <- struct{}{}
+	<-h.release
+	return nil
+}
+
+// closeRecorder records the output closing
+type closeRecorder struct {
+	closed atomic.Bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestLoggerConfigureWhileWriting(t *testing.T) {
+	l, err := NewLogger(LogConfig{}, "test", &bytes.Buffer{})
+	require.NoError(t, err)
+	defer l.Close()
+	h := &blockingHandler{Handler: slog.NewTextHandler(io.Discard, nil), entered: make(chan struct{}), release: make(chan struct{})}
+	output := &closeRecorder{}
+	l.state.lock.Lock()
+	l.state.base, l.state.closer = h, output
+	l.state.lock.Unlock()
+	go l.Info("record")
+	<-h.entered
+	configured := make(chan error)
+	go func() { configured <- l.Configure(LogConfig{}) }()
+	select {
+	case <-configured:
+		t.Fatal("the output is replaced during the write")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.False(t, output.closed.Load())
+	close(h.release)
+	require.NoError(t, <-configured)
+	require.True(t, output.closed.Load())
+}
+
+func TestLoggerFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	l, err := NewLogger(LogConfig{Output: "file", Format: "json", MaxSize: 1, MaxFiles: 2}, "test", nil)
+	require.NoError(t, err)
+	msg := strings.Repeat("x", 300)
+	for range 10 {
+		l.Info(msg)
+	}
+	l.Close()
+	path := filepath.Join(StateDir("test"), "test.log")
+	require.Equal(t, filepath.Join(os.Getenv("XDG_STATE_HOME"), "test", "test.log"), path)
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(1024))
+	}
+	require.True(t, NotExists(path+".3"))
+	l.Info("after close") // must not panic
+}
+
+func TestLoggerJournald(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	saved := journalSocket
+	journalSocket = socket
+	defer func() { journalSocket = saved }()
+	l, err := NewLogger(LogConfig{Output: "journald"}, "test", nil)
+	require.NoError(t, err)
+	defer l.Close()
+	l.With("component", "ydisk").WithGroup("daemon").Warn("status", "error", "line1\nline2", "2nd", "x")
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	entry := string(buf[:n])
+	require.Contains(t, entry, "MESSAGE=status\n")
+	require.Contains(t, entry, "PRIORITY=4\n")
+	require.Contains(t, entry, "SYSLOG_IDENTIFIER=test\n")
+	require.Contains(t, entry, "COMPONENT=ydisk\n")
+	require.Contains(t, entry, "DAEMON_2ND=x\n")
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len("line1\nline2")))
+	require.Contains(t, entry, "DAEMON_ERROR\n"+string(size)+"line1\nline2\n")
+
+	l.Info("sending_message", "message", "body", "priority", "high")
+	n, err = conn.Read(buf)
+	require.NoError(t, err)
+	entry = string(buf[:n])
+	require.Equal(t, 1, strings.Count("\n"+entry, "\nMESSAGE="))
+	require.Contains(t, "\n"+entry, "\nMESSAGE=sending_message\n")
+	require.Contains(t, entry, "\nF_MESSAGE=body\n")
+	require.Equal(t, 1, strings.Count("\n"+entry, "\nPRIORITY="))
+	require.Contains(t, entry, "\nF_PRIORITY=high\n")
+}
+
+func TestJournalField(t *testing.T) {
+	require.Equal(t, "COMPONENT", journalField("component"))
+	require.Equal(t, "SOME_KEY_1", journalField("some-key.1"))
+	require.Equal(t, "F_PRIVATE", journalField("_private"))
+	require.Equal(t, "F1ST", journalField("1st"))
+	require.Equal(t, "F_MESSAGE", journalField("message"))
+	require.Equal(t, "F_PRIORITY", journalField("priority"))
+	require.Equal(t, "F_SYSLOG_IDENTIFIER", journalField("syslog_identifier"))
+	require.Equal(t, "F_CODE_LINE", journalField("code.line"))
+	require.Equal(t, "MESSAGES", journalField("messages"))
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// override describes the configuration setting that can be set by environment variable or command line flag
+type override struct {
+	field string // JSON path of the configuration field
+	env   string // environment variable name
+	flag  string // command line flag name
+	usage string // command line flag usage, the back-quoted word is the flag value name
+}
+
+// overrides are the configuration settings that can be overridden. Priority: flag > environment variable > file > default.
+var overrides = []override{
+	{"Conf", "YDGO_CONF", "daemon-config", "`Path` to the daemon config file"},
+	{"Profile", "YDGO_PROFILE", "profile", "Active daemon configuration profile `name` (empty for the default profile)"},
+	{"Theme", "YDGO_THEME", "theme", "Icons theme `name`: 'dark', 'light', 'auto' or user theme"},
+	{"Notifications", "YDGO_NOTIFICATIONS", "notifications", "Display desktop notifications"},
+	{"StartDaemon", "YDGO_START_DAEMON", "start-daemon", "Start the daemon on indicator start"},
+	{"StopDaemon", "YDGO_STOP_DAEMON", "stop-daemon", "Stop the daemon on indicator exit"},
+	{"HooksLimit", "YDGO_HOOKS_LIMIT", "hooks-limit", "Maximum `number` of concurrently executed hooks"},
+	{"Log.Format", "YDGO_LOG_FORMAT", "log-format", "Log `format`: 'text' or 'json'"},
+	{"Log.Output", "YDGO_LOG_OUTPUT", "log-output", "Log `output`: 'stdout', 'stderr', 'file' or 'journald'"},
+	{"Log.Level", "YDGO_LOG_LEVEL", "log-level", "Log `level`: 'debug', 'info', 'warn' or 'error'"},
+}
+
+// isBool returns true for the boolean setting
+func (o override) isBool() bool {
+	return (&Config{}).fieldByPath(o.field).Kind() == reflect.Bool
+}
+
+// overrideValue is the overridden setting value
+type overrideValue struct {
+	value  any    // the effective value
+	file   any    // the value from file (or default) that is saved to the configuration file
+	source string // "env" or "flag"
+}
+
+// Setting is the effective value of configuration setting and its source
+type Setting struct {
+	Field  string // JSON path of the configuration field
+	Value  string // the effective value
+	Source string // "default" (the value is the default one), "file", "env" or "flag"
+}
+
+// overrideFlag is the command line flag that stores its value into Params.Overrides
+type overrideFlag struct {
+	field  string
+	isBool bool
+	values map[string]string
+}
+
+// String implements flag.Value
+func (f *overrideFlag) String() string {
+	if f == nil || f.values == nil {
+		return ""
+	}
+	return f.values[f.field]
+}
+
+// Set implements flag.Value
+func (f *overrideFlag) Set(value string) error {
+	f.values[f.field] = value
+	return nil
+}
+
+// IsBoolFlag allows to use the boolean flags without value
+func (f *overrideFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// fieldByPath returns the configuration field value by its JSON path
+func (c *Config) fieldByPath(path string) reflect.Value {
+	v := reflect.ValueOf(c).Elem()
+	for name := range strings.SplitSeq(path, ".") {
+		v = v.FieldByName(name)
+	}
+	return v
+}
+
+// parseValue converts the string value into the value of field type
+func parseValue(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, fmt.Errorf("should be boolean")
+		}
+		v.SetBool(b)
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return v, fmt.Errorf("should be integer")
+		}
+		v.SetInt(int64(n))
+	}
+	return v, nil
+}
+
+// ApplyOverrides sets the configuration settings from environment variables and command line flags (the map of
+// field JSON paths to values from Params.Overrides). The overridden values are not saved to the configuration file.
+// The override is dropped when the setting is changed via setter.
+func (c *Config) ApplyOverrides(flags map[string]string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var errs ValidationError
+	for _, o := range overrides {
+		value, source := "", ""
+		if v, ok := os.LookupEnv(o.env); ok {
+			value, source = v, "env"
+		}
+		if v, ok := flags[o.field]; ok {
+			value, source = v, "flag"
+		}
+		if source == "" {
+			continue
+		}
+		field := c.fieldByPath(o.field)
+		v, err := parseValue(field.Type(), value)
+		if err != nil {
+			name := "-" + o.flag
+			if source == "env" {
+				name = o.env
+			}
+			errs.add(o.field, fmt.Errorf("wrong %s value '%s': %v", name, value, err))
+			continue
+		}
+		if c.overridden == nil {
+			c.overridden = make(map[string]overrideValue)
+		}
+		file := field.Interface()
+		if prev, ok := c.overridden[o.field]; ok {
+			file = prev.file
+		}
+		c.overridden[o.field] = overrideValue{value: v.Interface(), file: file, source: source}
+		field.Set(v)
+	}
+	if err := c.validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+	return errs.err()
+}
+
+// applyOverridden sets the overridden values into fresh configuration that is read from the file and keeps
+// the file values for saving. It has to be called under lock.
+func (c *Config) applyOverridden(fresh *Config) {
+	for path, o := range c.overridden {
+		field := fresh.fieldByPath(path)
+		o.file = field.Interface()
+		c.overridden[path] = o
+		field.Set(reflect.ValueOf(o.value))
+	}
+}
+
+// swapOverridden replaces the effective values of overridden settings by the file values (file is true) or back.
+// It has to be called under lock.
+func (c *Config) swapOverridden(file bool) {
+	for path, o := range c.overridden {
+		v := o.value
+		if file {
+			v = o.file
+		}
+		c.fieldByPath(path).Set(reflect.ValueOf(v))
+	}
+}
+
+// Settings returns the effective values and sources of the settings that can be overridden
+func (c *Config) Settings() []Setting {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	defaults := defaultConfig("", nil)
+	settings := make([]Setting, len(overrides))
+	for i, o := range overrides {
+		source := "file"
+		if reflect.DeepEqual(c.fieldByPath(o.field).Interface(), defaults.fieldByPath(o.field).Interface()) {
+			source = "default"
+		}
+		if ov, ok := c.overridden[o.field]; ok {
+			source = ov.source
+		}
+		settings[i] = Setting{Field: o.field, Value: fmt.Sprint(c.fieldByPath(o.field).Interface()), Source: source}
+	}
+	return settings
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	logger := SetupLogger(false, os.Stdout)
+	content := `{"Theme":"dark","Notifications":true,"StopDaemon":true,"Log":{"Format":"json"},
+		"Profiles":[{"Name":"work","Conf":"/work/config.cfg"},{"Name":"home","Conf":"/home/config.cfg"}]}`
+	newConfig := func(t *testing.T) (*Config, string) {
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		return cfg, testFile
+	}
+	t.Run("priority and sources", func(t *testing.T) {
+		t.Setenv("YDGO_THEME", "dark")
+		t.Setenv("YDGO_NOTIFICATIONS", "false")
+		t.Setenv("YDGO_LOG_LEVEL", "debug")
+		t.Setenv("YDGO_PROFILE", "work")
+		cfg, _ := newConfig(t)
+		defer cfg.Flush()
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Theme": "light", "HooksLimit": "5"}))
+		require.Equal(t, "light", cfg.GetTheme())
+		require.False(t, cfg.GetNotifications())
+		require.Equal(t, LogConfig{Format: "json", Level: "debug"}, cfg.GetLog())
+		_, active := cfg.GetProfiles()
+		require.Equal(t, "work", active)
+		require.Equal(t, "/work/config.cfg", cfg.GetConf())
+		sources := map[string]string{}
+		for _, s := range cfg.Settings() {
+			sources[s.Field] = s.Value + " " + s.Source
+		}
+		require.Equal(t, map[string]string{
+			"Conf":          os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg") + " default",
+			"Profile":       "work env",
+			"Theme":         "light flag",
+			"Notifications": "false env",
+			"StartDaemon":   "true default",
+			"StopDaemon":    "true file",
+			"HooksLimit":    "5 flag",
+			"Log.Format":    "json file",
+			"Log.Output":    " default",
+			"Log.Level":     "debug env",
+		}, sources)
+	})
+	t.Run("not saved", func(t *testing.T) {
+		t.Setenv("YDGO_LOG_OUTPUT", "stderr")
+		cfg, testFile := newConfig(t)
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Theme": "light", "Notifications": "false"}))
+		cfg.SetStartDaemon(false) // trigger saving
+		cfg.SetNotifications(false)
+		cfg.Flush()
+		require.Equal(t, "light", cfg.GetTheme()) // effective value is restored after saving
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Theme":"dark","Notifications":false,"StartDaemon":false,"StopDaemon":true`)
+		require.Contains(t, string(data), `"Log":{"Format":"json"}`)
+	})
+	t.Run("setter drops override", func(t *testing.T) {
+		cfg, testFile := newConfig(t)
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Theme": "light"}))
+		cfg.SetTheme("light")
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Theme":"light"`)
+		require.Equal(t, "file", cfg.Settings()[2].Source)
+	})
+	t.Run("wrong values", func(t *testing.T) {
+		t.Setenv("YDGO_STOP_DAEMON", "maybe")
+		cfg, _ := newConfig(t)
+		defer cfg.Flush()
+		err := cfg.ApplyOverrides(map[string]string{"Theme": "../blue", "HooksLimit": "many", "Profile": "office"})
+		require.EqualError(t, err, `StopDaemon: wrong YDGO_STOP_DAEMON value 'maybe': should be boolean
+HooksLimit: wrong -hooks-limit value 'many': should be integer
+Theme: wrong theme name: '../blue' (should be 'dark', 'light', 'auto' or user theme directory name)
+Profile: unknown profile: 'office'`)
+	})
+	t.Run("profile is not saved", func(t *testing.T) {
+		cfg, testFile := newConfig(t)
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Profile": "home"}))
+		require.Equal(t, "/home/config.cfg", cfg.GetConf())
+		cfg.SetStopDaemon(false) // trigger saving
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), `"Profile":"home"`)
+		cfg, testFile = newConfig(t)
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Profile": "home"}))
+		require.NoError(t, cfg.SetProfile("work")) // setter drops override
+		cfg.Flush()
+		data, err = os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Profile":"work"`)
+	})
+	t.Run("kept on reload", func(t *testing.T) {
+		reloadDelay = 20 * time.Millisecond
+		cfg, testFile := newConfig(t)
+		defer cfg.Flush()
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Theme": "light"}))
+		changes, err := cfg.Watch()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"dark","Notifications":false}`), 0600))
+		require.Equal(t, []string{"Notifications", "StopDaemon", "Profiles", "Log"}, receive(changes, time.Second))
+		require.Equal(t, "light", cfg.GetTheme())
+		cfg.SetStopDaemon(true)
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Theme":"dark","Notifications":false`)
+	})
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Profile is the named daemon configuration. The default profile (with empty name) is formed by the Conf,
+// StartDaemon and StopDaemon fields of Config.
+type Profile struct {
+	Name        string // profile name
+	Conf        string // path to daemon config file
+	StartDaemon bool   // start daemon on app start or profile activation
+	StopDaemon  bool   // stop daemon on app exit or profile deactivation
+}
+
+// validateProfiles checks the profiles and the active profile name
+func (c *Config) validateProfiles(errs *ValidationError) {
+	names := make([]string, 0, len(c.Profiles))
+	for n, p := range c.Profiles {
+		path := fmt.Sprintf("Profiles[%d]", n)
+		switch {
+		case strings.TrimSpace(p.Name) == "":
+			errs.add(path+".Name", fmt.Errorf("empty profile name"))
+		case slices.Contains(names, p.Name):
+			errs.add(path+".Name", fmt.Errorf("duplicated profile name: '%s'", p.Name))
+		}
+		if strings.TrimSpace(p.Conf) == "" {
+			errs.add(path+".Conf", fmt.Errorf("empty daemon config path for profile '%s'", p.Name))
+		}
+		names = append(names, p.Name)
+	}
+	if c.Profile != "" && !slices.Contains(names, c.Profile) {
+		errs.add("Profile", fmt.Errorf("unknown profile: '%s'", c.Profile))
+	}
+}
+
+// active returns the active profile or nil for the default profile. It has to be called under lock.
+func (c *Config) active() *Profile {
+	if c.Profile == "" {
+		return nil
+	}
+	for n := range c.Profiles {
+		if c.Profiles[n].Name == c.Profile {
+			return &c.Profiles[n]
+		}
+	}
+	return nil
+}
+
+// activeFor returns the active profile for the field or nil when the field of the default profile has to be used.
+// The setting overridden by environment variable or command line flag is used for all profiles.
+// It has to be called under lock.
+func (c *Config) activeFor(field string) *Profile {
+	if _, ok := c.overridden[field]; ok {
+		return nil
+	}
+	return c.active()
+}
+
+// GetProfiles returns the names of configured profiles (the default profile is not included) and
+// the active profile name (empty for the default profile)
+func (c *Config) GetProfiles() ([]string, string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	names := make([]string, len(c.Profiles))
+	for n, p := range c.Profiles {
+		names[n] = p.Name
+	}
+	return names, c.Profile
+}
+
+// GetProfile returns the effective settings of the profile by its name (empty for the default profile)
+func (c *Config) GetProfile(name string) (Profile, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if name == "" {
+		return Profile{Conf: c.Conf, StartDaemon: c.StartDaemon, StopDaemon: c.StopDaemon}, true
+	}
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			// the overridden settings are used for all profiles
+			if _, ok := c.overridden["Conf"]; ok {
+				p.Conf = c.Conf
+			}
+			if _, ok := c.overridden["StartDaemon"]; ok {
+				p.StartDaemon = c.StartDaemon
+			}
+			if _, ok := c.overridden["StopDaemon"]; ok {
+				p.StopDaemon = c.StopDaemon
+			}
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// SetProfile activates the profile by its name (empty for the default profile) and triggers delayed saving of
+// configuration to the disk
+func (c *Config) SetProfile(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if name != "" && !slices.ContainsFunc(c.Profiles, func(p Profile) bool { return p.Name == name }) {
+		return fmt.Errorf("unknown profile: '%s'", name)
+	}
+	c.Profile = name
+	c.touch("Profile")
+	return nil
+}
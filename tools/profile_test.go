@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles(t *testing.T) {
+	logger := SetupLogger(false, os.Stdout)
+	content := `{"Conf":"/default.cfg","StartDaemon":true,"StopDaemon":false,
+"Profiles":[{"Name":"work","Conf":"/work.cfg","StopDaemon":true}],"Profile":"work"}`
+	t.Run("active profile", func(t *testing.T) {
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		names, active := cfg.GetProfiles()
+		require.Equal(t, []string{"work"}, names)
+		require.Equal(t, "work", active)
+		require.Equal(t, "/work.cfg", cfg.GetConf())
+		require.False(t, cfg.GetStartDaemon())
+		require.True(t, cfg.GetStopDaemon())
+		cfg.SetStartDaemon(true) // changes the active profile
+		require.NoError(t, cfg.SetProfile(""))
+		require.Equal(t, "/default.cfg", cfg.GetConf())
+		require.False(t, cfg.GetStopDaemon())
+		require.EqualError(t, cfg.SetProfile("home"), "unknown profile: 'home'")
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"StartDaemon":true,"StopDaemon":false`)
+		require.Contains(t, string(data), `"Profiles":[{"Name":"work","Conf":"/work.cfg","StartDaemon":true,"StopDaemon":true}]`)
+		require.NotContains(t, string(data), `"Profile":`)
+		p, ok := cfg.GetProfile("")
+		require.True(t, ok)
+		require.Equal(t, Profile{Conf: "/default.cfg", StartDaemon: true}, p)
+		_, ok = cfg.GetProfile("home")
+		require.False(t, ok)
+	})
+	t.Run("overridden settings", func(t *testing.T) {
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		defer cfg.Flush()
+		require.NoError(t, cfg.ApplyOverrides(map[string]string{"Conf": "/flag.cfg", "StopDaemon": "false"}))
+		require.Equal(t, "/flag.cfg", cfg.GetConf())
+		require.False(t, cfg.GetStartDaemon())
+		require.False(t, cfg.GetStopDaemon())
+		p, ok := cfg.GetProfile("work")
+		require.True(t, ok)
+		require.Equal(t, Profile{Name: "work", Conf: "/flag.cfg"}, p)
+	})
+	t.Run("validation", func(t *testing.T) {
+		wrong := `{"Profiles":[{"Name":"work","Conf":"/work.cfg"},{"Name":"work"},{"Name":" ","Conf":"/x.cfg"}],"Profile":"home"}`
+		testFile := makeTempCfgFile(t, &wrong)
+		_, err := NewConfig(testFile, time.Hour, logger)
+		require.ErrorContains(t, err, `Profiles[1].Name: duplicated profile name: 'work'
+Profiles[1].Conf: empty daemon config path for profile 'work'
+Profiles[2].Name: empty profile name
+Profile: unknown profile: 'home'`)
+	})
+}
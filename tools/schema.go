@@ -0,0 +1,309 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConfigVersion is the current version of the configuration file format
+const ConfigVersion = 1
+
+// migration upgrades the raw configuration content to its version
+type migration struct {
+	version     int                  // the version after migration
+	description string               // what the migration does
+	apply       func(map[string]any) // the raw content changes
+}
+
+// migrations are the ordered configuration format upgrades
+var migrations = []migration{
+	{
+		version:     1,
+		description: "add format version, use lower case theme name",
+		apply: func(raw map[string]any) {
+			if theme, ok := raw["Theme"].(string); ok {
+				raw["Theme"] = strings.ToLower(theme)
+			}
+		},
+	},
+}
+
+// errUnknownField is the error of object key that doesn't match any field. It is reported as warning except
+// the strict validation as the unknown fields are ignored by the configuration reading.
+var errUnknownField = errors.New("unknown field")
+
+// FieldError is the configuration validation error of the field specified by its JSON path
+type FieldError struct {
+	Path string // JSON path of the field, e.g. "Hooks[0].Command"
+	Err  error  // the field error
+}
+
+// Error implements error
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// ValidationError is the list of all configuration validation errors
+type ValidationError []FieldError
+
+// Error implements error
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// add appends the error for the field
+func (e *ValidationError) add(path string, err error) {
+	*e = append(*e, FieldError{Path: path, Err: err})
+}
+
+// err returns the ValidationError or nil when there is no errors
+func (e ValidationError) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// split separates the unknown field errors from the other ones
+func (e ValidationError) split() (errs, unknown ValidationError) {
+	for _, fe := range e {
+		if fe.Err == errUnknownField {
+			unknown = append(unknown, fe)
+		} else {
+			errs = append(errs, fe)
+		}
+	}
+	return errs, unknown
+}
+
+// warnUnknown logs the unknown fields of configuration file
+func warnUnknown(log *slog.Logger, unknown ValidationError) {
+	for _, fe := range unknown {
+		log.Warn("config_field", "field", fe.Path, "error", fe.Err)
+	}
+}
+
+// migrate upgrades the raw configuration content to the current format version. It returns the original version.
+func migrate(raw map[string]any) (int, error) {
+	version := 0
+	for key, v := range raw {
+		if strings.EqualFold(key, "Version") && key != "Version" {
+			delete(raw, key) // keys are case-insensitive as encoding/json matches them
+			raw["Version"] = v
+		}
+	}
+	if v, ok := raw["Version"]; ok {
+		f, ok := v.(float64)
+		if !ok || f != float64(int(f)) || f < 0 {
+			return 0, ValidationError{{Path: "Version", Err: fmt.Errorf("wrong format version: %v", v)}}
+		}
+		version = int(f)
+	}
+	if version > ConfigVersion {
+		return version, fmt.Errorf("config file format version %d is newer than supported version %d", version, ConfigVersion)
+	}
+	for _, m := range migrations {
+		if m.version > version {
+			m.apply(raw)
+			raw["Version"] = float64(m.version) // as JSON number is decoded
+		}
+	}
+	return version, nil
+}
+
+// jsonField returns the struct field that matches the JSON key (case-insensitive as encoding/json does) and its name in JSON
+func jsonField(t reflect.Type, key string) (reflect.StructField, string, bool) {
+	for n := range t.NumField() {
+		f := t.Field(n)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return f, name, true
+		}
+	}
+	return reflect.StructField{}, "", false
+}
+
+// jsonType returns JSON type name for Go type
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	}
+	return "integer"
+}
+
+// joinPath adds the field name to the JSON path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// checkFields checks the raw JSON value against the type: it reports unknown object keys and wrong value types
+func checkFields(path string, v any, t reflect.Type) ValidationError {
+	var errs ValidationError
+	if v == nil {
+		return nil // null is allowed for any field
+	}
+	wrongType := func() ValidationError {
+		errs.add(path, fmt.Errorf("wrong value type (should be %s)", jsonType(t)))
+		return errs
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return wrongType()
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			f, name, ok := jsonField(t, key)
+			if !ok {
+				errs.add(joinPath(path, key), errUnknownField)
+				continue
+			}
+			errs = append(errs, checkFields(joinPath(path, name), m[key], f.Type)...)
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return wrongType()
+		}
+		for key, val := range m {
+			errs = append(errs, checkFields(joinPath(path, key), val, t.Elem())...)
+		}
+	case reflect.Slice:
+		a, ok := v.([]any)
+		if !ok {
+			return wrongType()
+		}
+		for i, val := range a {
+			errs = append(errs, checkFields(fmt.Sprintf("%s[%d]", path, i), val, t.Elem())...)
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return wrongType()
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return wrongType()
+		}
+	default:
+		if f, ok := v.(float64); !ok || f != float64(int(f)) {
+			return wrongType()
+		}
+	}
+	return errs
+}
+
+// parseConfig parses the configuration file content into cfg. The content of older format versions is migrated
+// in memory. It returns the format version of the content, the unknown fields and the error. All other field
+// errors are returned at once as ValidationError. The unknown fields are ignored.
+func parseConfig(data []byte, cfg *Config) (int, ValidationError, error) {
+	raw := map[string]any{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, nil, fmt.Errorf("parsing config file error: %v", err)
+	}
+	version, err := migrate(raw)
+	if err != nil {
+		return version, nil, err
+	}
+	errs, unknown := checkFields("", raw, reflect.TypeFor[Config]()).split()
+	data, _ = json.Marshal(raw)
+	_ = json.Unmarshal(data, cfg) // the fields with wrong types are already reported and keep default values
+	if err := cfg.validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+	return version, unknown, errs.err()
+}
+
+// backupConfig saves the original configuration file content before migration. It returns the backup file path.
+func backupConfig(path string, version int, data []byte) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("can't backup config file: %v", err)
+	}
+	return backup, nil
+}
+
+// ReadConfig reads and validates the configuration file without its changing (the content of older format versions
+// is migrated in memory only). It returns the effective configuration and the file format version. The default
+// configuration is returned when the file doesn't exist. The unknown fields are reported as errors in strict mode
+// and ignored otherwise. The returned configuration is for inspection only: its setters must not be used.
+func ReadConfig(path string, strict bool) (*Config, int, error) {
+	cfg := defaultConfig(path, nil)
+	if NotExists(path) {
+		return cfg, ConfigVersion, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading config file error: %v", err)
+	}
+	if len(data) == 0 {
+		return cfg, ConfigVersion, nil
+	}
+	version, unknown, err := parseConfig(data, cfg)
+	if strict && len(unknown) > 0 {
+		errs, _ := err.(ValidationError) // the other errors are returned before the fields checking
+		err = append(unknown, errs...)
+	}
+	if err != nil {
+		return nil, version, err
+	}
+	return cfg, version, nil
+}
+
+// MigrateConfig upgrades the configuration file to the current format version. The original file is saved
+// as <path>.v<version>.bak. It returns the backup path or empty string when the file is already up to date.
+func MigrateConfig(path string) (string, error) {
+	cfg, version, err := ReadConfig(path, true) // the unknown fields would be lost by migration
+	if err != nil {
+		return "", err
+	}
+	if version == ConfigVersion {
+		return "", nil
+	}
+	data, _ := os.ReadFile(path)
+	backup, err := backupConfig(path, version, data)
+	if err != nil {
+		return "", err
+	}
+	data, _ = json.Marshal(cfg)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("can't save config file: %v", err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Run("all errors", func(t *testing.T) {
+		content := `{"Version":1,"Theme":"../blue","Notifications":"yes","Unknown":1,
+			"Hooks":[{"Event":"error","Command":"true","Extra":true},{"Event":"never","Command":"true"}],
+			"Log":{"Format":"xml","Levels":{"ui":"loud"},"MaxFiles":1.5}}`
+		_, unknown, err := parseConfig([]byte(content), defaultConfig("", nil))
+		require.EqualError(t, unknown, "Hooks[0].Extra: unknown field\nUnknown: unknown field")
+		require.EqualError(t, err, `Log.MaxFiles: wrong value type (should be integer)
+Notifications: wrong value type (should be boolean)
+Theme: wrong theme name: '../blue' (should be 'dark', 'light', 'auto' or user theme directory name)
+Hooks[1]: wrong hook event: 'never'
+Log.Format: wrong log format: 'xml' (should be 'text' or 'json')
+Log.Levels.ui: wrong log level for component 'ui': 'loud'`)
+		var ve ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Len(t, ve, 6)
+	})
+	t.Run("case insensitive keys", func(t *testing.T) {
+		cfg := defaultConfig("", nil)
+		version, _, err := parseConfig([]byte(`{"version":1,"theme":"light"}`), cfg)
+		require.NoError(t, err)
+		require.Equal(t, 1, version)
+		require.Equal(t, "light", cfg.Theme)
+	})
+	t.Run("migration", func(t *testing.T) {
+		cfg := defaultConfig("", nil)
+		version, _, err := parseConfig([]byte(`{"Theme":"Light"}`), cfg)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+		require.Equal(t, "light", cfg.Theme)
+		require.Equal(t, ConfigVersion, cfg.Version)
+	})
+	t.Run("newer version", func(t *testing.T) {
+		_, _, err := parseConfig([]byte(`{"Version":100}`), defaultConfig("", nil))
+		require.EqualError(t, err, "config file format version 100 is newer than supported version 1")
+		_, _, err = parseConfig([]byte(`{"Version":"1"}`), defaultConfig("", nil))
+		require.EqualError(t, err, "Version: wrong format version: 1")
+	})
+	t.Run("unknown fields", func(t *testing.T) {
+		testFile := makeTempCfgFile(t, nil)
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"light","Other":1}`), 0600))
+		_, _, err := ReadConfig(testFile, true)
+		require.EqualError(t, err, "Other: unknown field")
+		cfg, _, err := ReadConfig(testFile, false)
+		require.NoError(t, err)
+		require.Equal(t, "light", cfg.Theme)
+		out := &bytes.Buffer{}
+		cfg, err = NewConfig(testFile, time.Hour, SetupLogger(false, out))
+		require.NoError(t, err)
+		defer cfg.Flush()
+		require.Equal(t, "light", cfg.GetTheme())
+		require.Contains(t, out.String(), "config_field field=Other error=\"unknown field\"")
+	})
+}
+
+func TestMigrateConfig(t *testing.T) {
+	content := `{"Theme":"DARK","StopDaemon":true}`
+	testFile := makeTempCfgFile(t, &content)
+	t.Run("read without changes", func(t *testing.T) {
+		cfg, version, err := ReadConfig(testFile, true)
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+		require.Equal(t, "dark", cfg.Theme)
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+	t.Run("migrate", func(t *testing.T) {
+		backup, err := MigrateConfig(testFile)
+		require.NoError(t, err)
+		require.Equal(t, testFile+".v0.bak", backup)
+		data, err := os.ReadFile(backup)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+		data, err = os.ReadFile(testFile)
+		require.NoError(t, err)
+		raw := map[string]any{}
+		require.NoError(t, json.Unmarshal(data, &raw))
+		require.Equal(t, 1.0, raw["Version"])
+		require.Equal(t, "dark", raw["Theme"])
+		require.Equal(t, true, raw["StopDaemon"])
+	})
+	t.Run("up to date", func(t *testing.T) {
+		backup, err := MigrateConfig(testFile)
+		require.NoError(t, err)
+		require.Empty(t, backup)
+	})
+	t.Run("no file", func(t *testing.T) {
+		cfg, version, err := ReadConfig(makeTempCfgFile(t, nil), true)
+		require.NoError(t, err)
+		require.Equal(t, ConfigVersion, version)
+		require.Equal(t, "dark", cfg.Theme)
+	})
+	t.Run("migration on start", func(t *testing.T) {
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, time.Hour, SetupLogger(false, os.Stdout))
+		require.NoError(t, err)
+		cfg.Flush()
+		require.FileExists(t, testFile+".v0.bak")
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `{"Version":1,`)
+	})
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SdNotifier sends the service state notifications to systemd via sd_notify protocol.
+// All its methods are no-op when the application is not started by systemd with Type=notify.
+type SdNotifier struct {
+	addr     *net.UnixAddr // notification socket address, nil when notifications are not expected
+	watchdog time.Duration // watchdog timeout, 0 when watchdog is not enabled
+}
+
+// NewSdNotifier returns the SdNotifier configured from NOTIFY_SOCKET, WATCHDOG_USEC and WATCHDOG_PID environment variables
+func NewSdNotifier() *SdNotifier {
+	n := &SdNotifier{}
+	if socket := os.Getenv("NOTIFY_SOCKET"); socket != "" {
+		n.addr = &net.UnixAddr{Name: socket, Net: "unixgram"}
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return n // watchdog is expected from another process
+	}
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+	return n
+}
+
+// Notify sends the state lines (like "READY=1" or "STATUS=...") in one notification
+func (n *SdNotifier) Notify(state ...string) error {
+	if n.addr == nil {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(strings.Join(state, "\n")))
+	return err
+}
+
+// WatchdogInterval returns the recommended interval for sending WATCHDOG=1 heartbeats (half of the watchdog timeout)
+// or 0 when watchdog is not enabled.
+func (n *SdNotifier) WatchdogInterval() time.Duration {
+	return n.watchdog / 2
+}
+
+// quoteUnitArg quotes the command line argument for systemd unit file
+func quoteUnitArg(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%") // % is the specifier prefix in unit files
+	if strings.ContainsAny(arg, " \t\"'\\") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// ServiceUnit returns the systemd user unit file content for the application with notify service type and watchdog
+func ServiceUnit(description, documentation string, watchdog time.Duration, command ...string) string {
+	args := make([]string, len(command))
+	for i, a := range command {
+		args[i] = quoteUnitArg(a)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=%s
+Documentation=%s
+PartOf=graphical-session.target
+After=graphical-session.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+Restart=on-failure
+WatchdogSec=%d
+
+[Install]
+WantedBy=graphical-session.target
+`, description, documentation, strings.Join(args, " "), int(watchdog.Seconds()))
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSdNotifier(t *testing.T) {
+	t.Run("not under systemd", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+		t.Setenv("WATCHDOG_USEC", "")
+		n := NewSdNotifier()
+		require.NoError(t, n.Notify("READY=1"))
+		require.Zero(t, n.WatchdogInterval())
+	})
+	t.Run("notify", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "notify.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+		require.NoError(t, err)
+		defer conn.Close()
+		t.Setenv("NOTIFY_SOCKET", socket)
+		t.Setenv("WATCHDOG_USEC", "30000000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+		n := NewSdNotifier()
+		require.Equal(t, 15*time.Second, n.WatchdogInterval())
+		require.NoError(t, n.Notify("READY=1", "STATUS=idle"))
+		buf := make([]byte, 256)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		l, err := conn.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, "READY=1\nSTATUS=idle", string(buf[:l]))
+	})
+	t.Run("watchdog for another process", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+		t.Setenv("WATCHDOG_USEC", "30000000")
+		t.Setenv("WATCHDOG_PID", "1")
+		require.Zero(t, NewSdNotifier().WatchdogInterval())
+	})
+	t.Run("no socket", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "absent.sock"))
+		require.Error(t, NewSdNotifier().Notify("READY=1"))
+	})
+}
+
+func TestServiceUnit(t *testing.T) {
+	unit := ServiceUnit("Test app", "https://example.com", time.Minute, "/usr/bin/app", "-config=/home/user/my dir/50%.cfg")
+	require.Contains(t, unit, "Description=Test app\n")
+	require.Contains(t, unit, "Documentation=https://example.com\n")
+	require.Contains(t, unit, "Type=notify\n")
+	require.Contains(t, unit, "WatchdogSec=60\n")
+	require.Contains(t, unit, `ExecStart=/usr/bin/app "-config=/home/user/my dir/50%%.cfg"`+"\n")
+}
@@ -16,6 +16,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/slytomcat/yd-go/hooks"
 )
 
 var (
@@ -91,7 +93,7 @@ func (d *Delayer) Act() {
 	}
 }
 
-// Stop stops the Delayer and performs action if it was scheduled.
+// Stop stops the Delayer and performs action if it was scheduled (including the Act call that is not handled yet).
 // if will be released after finish of the action if it was scheduled or immediately if nothing scheduled.
 // It should be called when the Delayer is no longer needed to avoid goroutine leaks.
 func (d *Delayer) Stop() {
@@ -123,10 +125,14 @@ func (d *Delayer) loop(ctx context.Context, action func(), delay time.Duration)
 		case <-tick:
 			stop = false
 		case <-d.chStop:
-			if tick == nil {
-				<-waitFinish // wait for finish of already started execution
-				d.finish()
-				return
+			select {
+			case <-d.chAct: // the action is scheduled but the loop has not handled it yet
+			default:
+				if tick == nil {
+					<-waitFinish // wait for finish of already started execution
+					d.finish()
+					return
+				}
 			}
 			stop = true
 		}
@@ -145,29 +151,72 @@ func (d *Delayer) loop(ctx context.Context, action func(), delay time.Duration)
 
 // Config is application configuration
 type Config struct {
-	lock          sync.Mutex   // lock for configuration fields
-	path          string       // path to configuration file
-	delayer       *Delayer     // delayer for saving configuration to the disk
-	log           *slog.Logger // logger for logging configuration saving errors
-	Conf          string       // path to daemon config file
-	Theme         string       // icons theme name
-	Notifications bool         // display desktop notification
-	StartDaemon   bool         // start daemon on app start
-	StopDaemon    bool         // stop daemon on app exit
+	lock          sync.Mutex               // lock for configuration fields
+	path          string                   // path to configuration file
+	delayer       *Delayer                 // delayer for saving configuration to the disk
+	log           *slog.Logger             // logger for logging configuration saving errors
+	dirty         map[string]bool          // names of fields changed by setters and not saved yet
+	watch         *configWatch             // configuration file watching state, nil when the file is not watched
+	overridden    map[string]overrideValue // settings overridden by environment variables and command line flags
+	Version       int                      // configuration file format version
+	Conf          string                   // path to daemon config file
+	Theme         string                   // icons theme name
+	UsageBar      bool                     `json:",omitempty"` // show the disk space usage bar on the idle icon
+	Animation     AnimationConfig          `json:",omitzero"`  // busy icon animation configuration
+	Click         ClickConfig              `json:",omitzero"`  // tray icon click actions
+	Last          LastConfig               `json:",omitzero"`  // last synchronized items menu configuration
+	Notifications bool                     // display desktop notification
+	NotifyEvents  map[string]bool          `json:",omitempty"` // notifications switches by event name, missing events use NotifyEvents defaults
+	StartDaemon   bool                     // start daemon on app start
+	StopDaemon    bool                     // stop daemon on app exit
+	Hooks         []hooks.Hook             `json:",omitempty"` // user commands executed on events
+	HooksLimit    int                      `json:",omitempty"` // maximum number of concurrently executed hooks, 0 means the default limit
+	Profiles      []Profile                `json:",omitempty"` // additional daemon configurations
+	Profile       string                   `json:",omitempty"` // active profile name, empty for the default profile
+	Log           LogConfig                `json:",omitzero"`  // logging configuration
 }
 
-// NewConfig returns the application configuration
-func NewConfig(cfgFilePath string, delay time.Duration, log *slog.Logger) (*Config, error) {
-	cfg := &Config{
+// defaultConfig returns the configuration with default values
+func defaultConfig(cfgFilePath string, log *slog.Logger) *Config {
+	return &Config{
 		path: cfgFilePath,
 		log:  log,
 		// fill it with default values
-		Conf:          os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg"), // path to daemon config file
-		Theme:         "dark",                                               // icons theme name
-		Notifications: true,                                                 // display desktop notification
-		StartDaemon:   true,                                                 // start daemon on app start
-		StopDaemon:    false,                                                // stop daemon on app closure
+		Version:       ConfigVersion,  // configuration file format version
+		Conf:          DaemonConfig(), // path to daemon config file
+		Theme:         "dark",         // icons theme name
+		Notifications: true,           // display desktop notification
+		StartDaemon:   true,           // start daemon on app start
+		StopDaemon:    false,          // stop daemon on app closure
+	}
+}
+
+// validate checks the configuration values. It returns ValidationError with all found errors.
+func (c *Config) validate() error {
+	var errs ValidationError
+	if !validThemeName(c.Theme) {
+		errs.add("Theme", fmt.Errorf("wrong theme name: '%s' (should be 'dark', 'light', 'auto' or user theme directory name)", c.Theme))
+	}
+	for n, h := range c.Hooks {
+		if err := h.Validate(); err != nil {
+			errs.add(fmt.Sprintf("Hooks[%d]", n), err)
+		}
 	}
+	if c.HooksLimit < 0 {
+		errs.add("HooksLimit", fmt.Errorf("negative hooks limit"))
+	}
+	errs = append(errs, c.Animation.validate("Animation")...)
+	errs = append(errs, c.Click.validate("Click")...)
+	errs = append(errs, c.Last.validate("Last")...)
+	errs = append(errs, c.Log.validate("Log")...)
+	c.validateProfiles(&errs)
+	c.validateNotifyEvents(&errs)
+	return errs.err()
+}
+
+// NewConfig returns the application configuration
+func NewConfig(cfgFilePath string, delay time.Duration, log *slog.Logger) (*Config, error) {
+	cfg := defaultConfig(cfgFilePath, log)
 	cfg.delayer = NewDelayer(cfg.save, delay)
 	returnError := func(err error) (*Config, error) {
 		cfg.delayer.Stop() // stop the delayed actioner to avoid goroutine leak
@@ -195,12 +244,18 @@ func NewConfig(cfgFilePath string, delay time.Duration, log *slog.Logger) (*Conf
 			cfg.save()      // try to save default config to the file
 			return cfg, nil // return default config
 		}
-		err = json.Unmarshal(data, cfg)
+		version, unknown, err := parseConfig(data, cfg)
 		if err != nil {
-			return returnError(fmt.Errorf("parsing config file error: %v", err))
+			return returnError(err)
 		}
-		if cfg.Theme != "dark" && cfg.Theme != "light" {
-			return returnError(fmt.Errorf("wrong theme name: '%s' (should be 'dark' or 'light')", cfg.Theme))
+		warnUnknown(log, unknown)
+		if version < ConfigVersion {
+			backup, err := backupConfig(cfgFilePath, version, data)
+			if err != nil {
+				return returnError(err)
+			}
+			log.Info("config_migrated", "from", version, "to", ConfigVersion, "backup", backup)
+			cfg.save() // save the migrated configuration
 		}
 	}
 	return cfg, nil
@@ -209,24 +264,60 @@ func NewConfig(cfgFilePath string, delay time.Duration, log *slog.Logger) (*Conf
 // save writes the configuration to the disk. It is used as action for DelayedActioner and should not be called directly.
 // In case of error it logs the error message but does not return it.
 func (c *Config) save() {
+	c.checkFile() // merge the external changes that are not handled yet to avoid their overwriting
 	c.lock.Lock()
+	c.swapOverridden(true) // the overridden values are not saved
 	data, _ := json.Marshal(c)
+	c.swapOverridden(false)
+	log := c.log
+	c.dirty = nil // all changes are going to be saved
+	if c.watch != nil {
+		c.watch.saved = data
+	}
 	c.lock.Unlock()
 	err := os.WriteFile(c.path, data, 0600)
 	if err != nil {
-		c.log.Warn("can't save config file", "error", err)
+		log.Warn("can't save config file", "error", err)
 	}
 }
 
 // Flush saves the configuration to the disk immediately if it was changed earlier.
 // It can be used to save configuration before application exit without waiting for timeout.
 // if waits for save finish if the configuration was changed and scheduled for saving, otherwise it returns immediately.
+// It also stops the configuration file watching.
 func (c *Config) Flush() {
+	c.stopWatch()
 	c.delayer.Stop() // stop the delayer and perform save if it was scheduled before to avoid data loss
 }
 
+// touch marks the field as changed and triggers delayed saving of configuration to the disk. It has to be called under lock.
+func (c *Config) touch(field string) {
+	if c.dirty == nil {
+		c.dirty = make(map[string]bool)
+	}
+	c.dirty[field] = true
+	delete(c.overridden, field) // the value is set by user
+	c.delayer.Act()
+}
+
 // Getters and setters for configuration fields which can be changed via menu. Setters trigger delayed saving of configuration to the disk.
 
+// GetConf returns the path to daemon config file of the active profile
+func (c *Config) GetConf() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if p := c.activeFor("Conf"); p != nil {
+		return p.Conf
+	}
+	return c.Conf
+}
+
+// validThemeName checks that the theme name can be the name of built-in theme or user theme directory.
+// The availability of user theme is checked when the theme is loaded.
+func validThemeName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.Contains(name, "/")
+}
+
 // GetTheme returns the current theme name
 func (c *Config) GetTheme() string {
 	c.lock.Lock()
@@ -239,7 +330,22 @@ func (c *Config) SetTheme(theme string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	c.Theme = theme
-	c.delayer.Act()
+	c.touch("Theme")
+}
+
+// GetUsageBar returns the current value of UsageBar field
+func (c *Config) GetUsageBar() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.UsageBar
+}
+
+// SetUsageBar sets the value of UsageBar field and triggers delayed saving of configuration to the disk
+func (c *Config) SetUsageBar(usageBar bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.UsageBar = usageBar
+	c.touch("UsageBar")
 }
 
 // GetNotifications returns the current value of Notifications field
@@ -254,37 +360,88 @@ func (c *Config) SetNotifications(notifications bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	c.Notifications = notifications
-	c.delayer.Act()
+	c.touch("Notifications")
 }
 
-// GetStartDaemon returns the current value of StartDaemon field
+// GetStartDaemon returns the current value of StartDaemon field of the active profile
 func (c *Config) GetStartDaemon() bool {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if p := c.activeFor("StartDaemon"); p != nil {
+		return p.StartDaemon
+	}
 	return c.StartDaemon
 }
 
-// SetStartDaemon sets the value of StartDaemon field and triggers delayed saving of configuration to the disk
+// SetStartDaemon sets the value of StartDaemon field of the active profile and triggers delayed saving of configuration to the disk
 func (c *Config) SetStartDaemon(startDaemon bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if p := c.activeFor("StartDaemon"); p != nil {
+		p.StartDaemon = startDaemon
+		c.touch("Profiles")
+		return
+	}
 	c.StartDaemon = startDaemon
-	c.delayer.Act()
+	c.touch("StartDaemon")
 }
 
-// GetStopDaemon returns the current value of StopDaemon field
+// GetStopDaemon returns the current value of StopDaemon field of the active profile
 func (c *Config) GetStopDaemon() bool {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if p := c.activeFor("StopDaemon"); p != nil {
+		return p.StopDaemon
+	}
 	return c.StopDaemon
 }
 
-// SetStopDaemon sets the value of StopDaemon field and triggers delayed saving of configuration to the disk
+// SetStopDaemon sets the value of StopDaemon field of the active profile and triggers delayed saving of configuration to the disk
 func (c *Config) SetStopDaemon(stopDaemon bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if p := c.activeFor("StopDaemon"); p != nil {
+		p.StopDaemon = stopDaemon
+		c.touch("Profiles")
+		return
+	}
 	c.StopDaemon = stopDaemon
-	c.delayer.Act()
+	c.touch("StopDaemon")
+}
+
+// GetHooks returns the copy of configured hooks and the limit of concurrently executed hooks
+func (c *Config) GetHooks() ([]hooks.Hook, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]hooks.Hook(nil), c.Hooks...), c.HooksLimit
+}
+
+// GetAnimation returns the busy icon animation configuration
+func (c *Config) GetAnimation() AnimationConfig {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Animation
+}
+
+// GetLog returns the copy of logging configuration
+func (c *Config) GetLog() LogConfig {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	lc := c.Log
+	if lc.Levels != nil {
+		lc.Levels = make(map[string]string, len(c.Log.Levels))
+		for k, v := range c.Log.Levels {
+			lc.Levels[k] = v
+		}
+	}
+	return lc
+}
+
+// SetLogger replaces the logger that is used for logging configuration saving errors
+func (c *Config) SetLogger(log *slog.Logger) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.log = log
 }
 
 // SetupLogger initializes the logger for application
@@ -299,18 +456,31 @@ func SetupLogger(debug bool, out io.Writer) *slog.Logger {
 	return slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: logLevel}))
 }
 
-// GetParams read the command line parameters and returns configuration file path and boolean value for debug logging activation.
+// Params holds the application command line parameters
+type Params struct {
+	Config    string            // path to the indicator configuration file
+	Debug     bool              // debug logging activation
+	Command   string            // command for the application (the first non-flag argument), empty when no command is specified
+	Args      []string          // the command arguments
+	StatusBar string            // status bar output mode, empty for the tray icon mode
+	Overrides map[string]string // configuration settings set by flags: JSON path of the field -> value
+}
+
+// GetParams read the command line parameters and returns them.
 // When app is called with -h or -version or with wrong option it will call os.Exit().
-func GetParams(appName string, args []string, version string) (string, bool) {
+func GetParams(appName string, args []string, version string) Params {
 	var pv bool
-	var debug bool
-	var config string
+	p := Params{Overrides: map[string]string{}}
 	f := flag.NewFlagSet(appName, flag.ExitOnError)
-	f.BoolVar(&debug, "debug", false, "Allow debugging messages to be sent to stdout")
-	f.StringVar(&config, "config", "$HOME/.config/"+appName+"/default.cfg", "Path to the indicator configuration file")
+	f.BoolVar(&p.Debug, "debug", false, "Allow debugging messages to be sent to stdout")
+	f.StringVar(&p.Config, "config", DefaultConfig(appName), "Path to the indicator configuration file")
+	f.StringVar(&p.StatusBar, "statusbar", "", "Status bar output mode instead of tray icon: 'waybar', 'i3bar' or 'text'")
+	for _, o := range overrides {
+		f.Var(&overrideFlag{field: o.field, isBool: o.isBool(), values: p.Overrides}, o.flag, o.usage+" (overrides the configured one, env: "+o.env+")")
+	}
 	f.BoolVar(&pv, "version", false, "Print out version information and exit")
 	f.Usage = func() {
-		_, _ = fmt.Fprintf(f.Output(), "%s\nUsage:\n\n\t%s [-debug] [-config=<Path to indicator config>] [-version]\n\n", getVersion(appName, version), appName)
+		_, _ = fmt.Fprintf(f.Output(), "%s\nUsage:\n\n\t%s [-debug] [-config=<Path to indicator config>] [-statusbar=<mode>] [<setting options>] [-version] [<command> [<arguments>]]\n\n", getVersion(appName, version), appName)
 		f.PrintDefaults()
 	}
 	_ = f.Parse(args[1:])
@@ -318,7 +488,12 @@ func GetParams(appName string, args []string, version string) (string, bool) {
 		fmt.Print(getVersion(appName, version))
 		os.Exit(0)
 	}
-	return os.ExpandEnv(config), debug
+	p.Config = os.ExpandEnv(p.Config)
+	p.Command = f.Arg(0)
+	if f.NArg() > 1 {
+		p.Args = f.Args()[1:]
+	}
+	return p
 }
 
 func getVersion(appName, version string) string {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/slytomcat/yd-go/hooks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -91,6 +92,15 @@ func TestDelayedActioner(t *testing.T) {
 		require.InDelta(t, executionTime(d.Stop), exTime, float64(3*time.Millisecond)) // stop have to execute the action immediately if it was scheduled
 		require.True(t, cc.Called())
 	})
+	t.Run("stop right after act", func(t *testing.T) {
+		for range 100 { // the loop may not have handled the scheduling yet when Stop is called
+			cc := &callChecker{}
+			d := NewDelayer(cc.Call, time.Hour)
+			d.Act()
+			d.Stop() // have to perform the action even if it is not scheduled by the loop yet
+			require.True(t, cc.Called())
+		}
+	})
 
 	t.Run("stop while action execution", func(t *testing.T) {
 		exTime := 60 * time.Millisecond
@@ -170,7 +180,7 @@ func TestDelayedActioner(t *testing.T) {
 }
 
 func TestConfig(t *testing.T) {
-	defaultConfigContent := `{"Conf":"` + os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg") + `","Theme":"dark","Notifications":true,"StartDaemon":true,"StopDaemon":false}`
+	defaultConfigContent := `{"Version":1,"Conf":"` + os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg") + `","Theme":"dark","Notifications":true,"StartDaemon":true,"StopDaemon":false}`
 	emptyJSONContent := "{}"
 	logger := SetupLogger(false, os.Stdout)
 	t.Run("no save on exit without changes", func(t *testing.T) {
@@ -230,6 +240,7 @@ func TestConfig(t *testing.T) {
 			path:          testFile,
 			delayer:       cfg.delayer,
 			log:           logger,
+			Version:       ConfigVersion,
 			Conf:          os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg"),
 			Theme:         "dark",
 			Notifications: true,
@@ -246,10 +257,49 @@ func TestConfig(t *testing.T) {
 		require.Nil(t, cfg)
 	})
 	t.Run("incorrect theme", func(t *testing.T) {
-		bad := `{"Theme":"incorrect"}`
+		bad := `{"Theme":"in/correct"}`
+		testFile := makeTempCfgFile(t, &bad)
+		defer os.Remove(testFile)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.Error(t, err)
+		require.Nil(t, cfg)
+	})
+	t.Run("incorrect hook", func(t *testing.T) {
+		bad := `{"Hooks":[{"Event":"sync_finished","Command":""}]}`
 		testFile := makeTempCfgFile(t, &bad)
 		defer os.Remove(testFile)
 		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.EqualError(t, err, "Hooks[0]: empty hook command for event 'sync_finished'")
+		require.Nil(t, cfg)
+	})
+	t.Run("hooks", func(t *testing.T) {
+		content := `{"Hooks":[{"Event":"sync_finished","Command":"make backup","Timeout":10}],"HooksLimit":1}`
+		testFile := makeTempCfgFile(t, &content)
+		defer os.Remove(testFile)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		defer cfg.Flush()
+		hooksList, limit := cfg.GetHooks()
+		require.Equal(t, []hooks.Hook{{Event: "sync_finished", Command: "make backup", Timeout: 10}}, hooksList)
+		require.Equal(t, 1, limit)
+	})
+	t.Run("log", func(t *testing.T) {
+		content := `{"Log":{"Format":"json","Output":"file","Levels":{"ydisk":"debug"}}}`
+		testFile := makeTempCfgFile(t, &content)
+		defer os.Remove(testFile)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
+		require.NoError(t, err)
+		defer cfg.Flush()
+		lc := cfg.GetLog()
+		require.Equal(t, LogConfig{Format: "json", Output: "file", Levels: map[string]string{"ydisk": "debug"}}, lc)
+		lc.Levels["ydisk"] = "error" // the copy is returned
+		require.Equal(t, "debug", cfg.GetLog().Levels["ydisk"])
+	})
+	t.Run("incorrect log", func(t *testing.T) {
+		content := `{"Log":{"Output":"syslog"}}`
+		testFile := makeTempCfgFile(t, &content)
+		defer os.Remove(testFile)
+		cfg, err := NewConfig(testFile, time.Hour, logger)
 		require.Error(t, err)
 		require.Nil(t, cfg)
 	})
@@ -264,6 +314,7 @@ func TestConfig(t *testing.T) {
 			path:          testFile,
 			delayer:       cfg.delayer,
 			log:           logger,
+			Version:       ConfigVersion,
 			Conf:          os.ExpandEnv("$HOME/.config/yandex-disk/config.cfg"),
 			Theme:         "dark",
 			Notifications: true,
@@ -283,6 +334,7 @@ func TestConfig(t *testing.T) {
 			path:          testFile,
 			delayer:       cfg.delayer,
 			log:           logger,
+			Version:       ConfigVersion,
 			Conf:          "config.cfg",
 			Theme:         "light",
 			Notifications: false,
@@ -388,22 +440,47 @@ func TestGetParams(t *testing.T) {
 	tAppName := "testApp"
 	tVersion := "test"
 	t.Run("wo_params", func(t *testing.T) {
-		cfgPath, debug := GetParams(tAppName, []string{tAppName}, tVersion)
-		require.Equal(t, os.ExpandEnv("$HOME/.config/"+tAppName+"/default.cfg"), cfgPath)
-		require.False(t, debug)
+		p := GetParams(tAppName, []string{tAppName}, tVersion)
+		require.Equal(t, os.ExpandEnv("$HOME/.config/"+tAppName+"/default.cfg"), p.Config)
+		require.False(t, p.Debug)
+		require.Empty(t, p.Command)
 	})
 	t.Run("with_debug", func(t *testing.T) {
-		cfgPath, debug := GetParams(tAppName, []string{tAppName, "-debug"}, tVersion)
-		require.Equal(t, os.ExpandEnv("$HOME/.config/"+tAppName+"/default.cfg"), cfgPath)
-		require.True(t, debug)
+		p := GetParams(tAppName, []string{tAppName, "-debug"}, tVersion)
+		require.Equal(t, os.ExpandEnv("$HOME/.config/"+tAppName+"/default.cfg"), p.Config)
+		require.True(t, p.Debug)
 	})
 	t.Run("with_cfg", func(t *testing.T) {
 		emptyJSON := "{}"
 		cfgFile := makeTempCfgFile(t, &emptyJSON)
 		defer os.Remove(cfgFile)
-		cfgPath, debug := GetParams(tAppName, []string{tAppName, "-config=" + cfgFile}, tVersion)
-		require.Equal(t, cfgFile, cfgPath)
-		require.False(t, debug)
+		p := GetParams(tAppName, []string{tAppName, "-config=" + cfgFile}, tVersion)
+		require.Equal(t, cfgFile, p.Config)
+		require.False(t, p.Debug)
+	})
+	t.Run("with_command", func(t *testing.T) {
+		p := GetParams(tAppName, []string{tAppName, "-debug", "start"}, tVersion)
+		require.True(t, p.Debug)
+		require.Equal(t, "start", p.Command)
+	})
+	t.Run("with_statusbar", func(t *testing.T) {
+		p := GetParams(tAppName, []string{tAppName, "-statusbar=waybar"}, tVersion)
+		require.Equal(t, "waybar", p.StatusBar)
+		require.Empty(t, p.Command)
+	})
+	t.Run("with_command_arguments", func(t *testing.T) {
+		p := GetParams(tAppName, []string{tAppName, "config", "validate"}, tVersion)
+		require.Equal(t, "config", p.Command)
+		require.Equal(t, []string{"validate"}, p.Args)
+	})
+	t.Run("with_log_options", func(t *testing.T) {
+		p := GetParams(tAppName, []string{tAppName, "-log-format=json", "-log-output=file"}, tVersion)
+		require.Equal(t, map[string]string{"Log.Format": "json", "Log.Output": "file"}, p.Overrides)
+	})
+	t.Run("with_setting_options", func(t *testing.T) {
+		p := GetParams(tAppName, []string{tAppName, "-theme=light", "-notifications=false", "-stop-daemon", "-hooks-limit", "3", "start"}, tVersion)
+		require.Equal(t, map[string]string{"Theme": "light", "Notifications": "false", "StopDaemon": "true", "HooksLimit": "3"}, p.Overrides)
+		require.Equal(t, "start", p.Command)
 	})
 	t.Run("with_-h", func(t *testing.T) {
 		getOut := readStd(&os.Stderr)
@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+var reloadDelay = 200 * time.Millisecond // delay for reading the file after the last change event
+
+// configWatch is the state of configuration file watching
+type configWatch struct {
+	saved    []byte        // the configuration file content that was read or written last time
+	pending  []string      // names of changed fields that are not sent yet
+	kick     chan struct{} // channel to inform the watching loop about pending changes
+	changes  chan []string // names of the fields changed by external edit
+	stop     chan struct{} // channel to stop watching
+	done     chan struct{} // closed when watching is stopped
+	stopOnce sync.Once
+}
+
+// Watch starts the configuration file watching. When the file is changed by another program (editor or
+// configuration management tool) the changes are merged into the configuration: the fields changed via setters
+// and not saved yet keep their values, other fields get the values from the file. The names of the changed fields
+// are sent to the returned channel. Invalid file content is reported to log and ignored.
+// The watching is stopped by Flush.
+func (c *Config) Watch() (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("can't create config file watcher: %v", err)
+	}
+	// the directory is watched as editors often replace the file instead of writing into it
+	if err := watcher.Add(filepath.Dir(c.path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("can't watch config file: %v", err)
+	}
+	data, _ := os.ReadFile(c.path)
+	w := &configWatch{
+		saved:   data,
+		kick:    make(chan struct{}, 1),
+		changes: make(chan []string),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	c.lock.Lock()
+	c.watch = w
+	c.lock.Unlock()
+	go c.watchLoop(watcher, w)
+	return w.changes, nil
+}
+
+// stopWatch stops the configuration file watching and waits for its finish
+func (c *Config) stopWatch() {
+	c.lock.Lock()
+	w := c.watch
+	c.lock.Unlock()
+	if w == nil {
+		return
+	}
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// watchLoop handles the file watcher events until stop
+func (c *Config) watchLoop(watcher *fsnotify.Watcher, w *configWatch) {
+	defer close(w.done)
+	defer watcher.Close()
+	name := filepath.Clean(c.path)
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-w.stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == name && !event.Has(fsnotify.Chmod) {
+				reload = time.After(reloadDelay) // wait for the end of writing
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			c.logger().Warn("config_watch", "error", err)
+		case <-reload:
+			reload = nil
+			c.checkFile()
+		case <-w.kick:
+			c.lock.Lock()
+			fields := w.pending
+			w.pending = nil
+			c.lock.Unlock()
+			select {
+			case w.changes <- fields:
+			case <-w.stop:
+				return
+			}
+		}
+	}
+}
+
+// logger returns the current configuration logger
+func (c *Config) logger() *slog.Logger {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.log
+}
+
+// checkFile merges the external changes of configuration file when the file is watched and
+// schedules sending of changed fields names
+func (c *Config) checkFile() {
+	c.lock.Lock()
+	w := c.watch
+	c.lock.Unlock()
+	if w == nil {
+		return
+	}
+	fields := c.reload()
+	if len(fields) == 0 {
+		return
+	}
+	c.lock.Lock()
+	w.pending = append(w.pending, fields...)
+	c.lock.Unlock()
+	select {
+	case w.kick <- struct{}{}:
+	default: // the loop is already informed
+	}
+}
+
+// reload reads the configuration file and merges its content into configuration. It returns the names of changed fields.
+func (c *Config) reload() []string {
+	data, err := os.ReadFile(c.path)
+	if err != nil || len(data) == 0 {
+		return nil // the file is being replaced or truncated, wait for the next event
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if bytes.Equal(data, c.watch.saved) {
+		return nil // own or already handled changes
+	}
+	c.watch.saved = data
+	fresh := defaultConfig(c.path, c.log)
+	_, unknown, err := parseConfig(data, fresh)
+	if err != nil {
+		c.log.Warn("config_reload", "error", err)
+		return nil
+	}
+	warnUnknown(c.log, unknown)
+	c.applyOverridden(fresh)
+	changed := c.merge(fresh)
+	c.log.Debug("config_reload", "changed", changed, "kept", len(c.dirty))
+	return changed
+}
+
+// merge copies the exported fields from fresh configuration except the fields changed by setters and not saved yet.
+// It returns the names of changed fields. It has to be called under lock.
+func (c *Config) merge(fresh *Config) []string {
+	var changed []string
+	cur, upd := reflect.ValueOf(c).Elem(), reflect.ValueOf(fresh).Elem()
+	for n := range cur.NumField() {
+		f := cur.Type().Field(n)
+		if !f.IsExported() || c.dirty[f.Name] {
+			continue
+		}
+		if !reflect.DeepEqual(cur.Field(n).Interface(), upd.Field(n).Interface()) {
+			cur.Field(n).Set(upd.Field(n))
+			changed = append(changed, f.Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// receive returns the changed fields received from ch within timeout or nil
+func receive(ch <-chan []string, timeout time.Duration) []string {
+	select {
+	case fields := <-ch:
+		return fields
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+func TestConfigWatch(t *testing.T) {
+	logger := SetupLogger(true, os.Stdout)
+	reloadDelay = 20 * time.Millisecond
+	newWatched := func(t *testing.T, delay time.Duration) (*Config, <-chan []string, string) {
+		content := `{"Theme":"dark","Notifications":true}`
+		testFile := makeTempCfgFile(t, &content)
+		cfg, err := NewConfig(testFile, delay, logger)
+		require.NoError(t, err)
+		changes, err := cfg.Watch()
+		require.NoError(t, err)
+		return cfg, changes, testFile
+	}
+	t.Run("external change", func(t *testing.T) {
+		cfg, changes, testFile := newWatched(t, time.Hour)
+		defer cfg.Flush()
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"light","Notifications":true,"Conf":"/tmp/yd.cfg"}`), 0600))
+		require.ElementsMatch(t, []string{"Theme", "Conf"}, receive(changes, time.Second))
+		require.Equal(t, "light", cfg.GetTheme())
+		require.Equal(t, "/tmp/yd.cfg", cfg.GetConf())
+	})
+	t.Run("file replacement", func(t *testing.T) {
+		cfg, changes, testFile := newWatched(t, time.Hour)
+		defer cfg.Flush()
+		tmp := filepath.Join(filepath.Dir(testFile), "default.cfg.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte(`{"Theme":"dark","Notifications":false}`), 0600))
+		require.NoError(t, os.Rename(tmp, testFile))
+		require.Equal(t, []string{"Notifications"}, receive(changes, time.Second))
+		require.False(t, cfg.GetNotifications())
+	})
+	t.Run("pending changes are kept", func(t *testing.T) {
+		cfg, changes, testFile := newWatched(t, time.Hour)
+		cfg.SetNotifications(false)
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"light","Notifications":true}`), 0600))
+		require.Equal(t, []string{"Theme"}, receive(changes, time.Second))
+		require.False(t, cfg.GetNotifications())
+		cfg.Flush()
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Theme":"light","Notifications":false`)
+	})
+	t.Run("own save", func(t *testing.T) {
+		cfg, changes, _ := newWatched(t, 10*time.Millisecond)
+		defer cfg.Flush()
+		cfg.SetTheme("light")
+		require.Nil(t, receive(changes, 200*time.Millisecond))
+		require.Equal(t, "light", cfg.GetTheme())
+	})
+	t.Run("invalid content", func(t *testing.T) {
+		cfg, changes, testFile := newWatched(t, time.Hour)
+		defer cfg.Flush()
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"../blue"}`), 0600))
+		require.Nil(t, receive(changes, 200*time.Millisecond))
+		require.NoError(t, os.WriteFile(testFile, []byte(`bad,bad`), 0600))
+		require.Nil(t, receive(changes, 200*time.Millisecond))
+		require.Equal(t, "dark", cfg.GetTheme())
+	})
+	t.Run("unknown fields", func(t *testing.T) {
+		cfg, changes, testFile := newWatched(t, time.Hour)
+		defer cfg.Flush()
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"light","Notifications":true,"Other":1}`), 0600))
+		require.Equal(t, []string{"Theme"}, receive(changes, time.Second))
+		require.Equal(t, "light", cfg.GetTheme())
+	})
+	t.Run("save merges external changes", func(t *testing.T) {
+		cfg, _, testFile := newWatched(t, time.Hour)
+		cfg.SetStopDaemon(true)
+		require.NoError(t, os.WriteFile(testFile, []byte(`{"Theme":"light"}`), 0600))
+		cfg.Flush() // save before the reload
+		require.Equal(t, "light", cfg.GetTheme())
+		data, err := os.ReadFile(testFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Theme":"light"`)
+		require.Contains(t, string(data), `"StopDaemon":true`)
+	})
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xdgHome returns the directory from XDG base directory variable or the default one under $HOME.
+// Relative paths in variables are ignored as the XDG Base Directory Specification requires.
+func xdgHome(env, def string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), def)
+}
+
+// ConfigHome returns the base directory for user configuration files: $XDG_CONFIG_HOME or $HOME/.config
+func ConfigHome() string {
+	return xdgHome("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigDir returns the directory for application configuration files: $XDG_CONFIG_HOME/<appName>
+func ConfigDir(appName string) string {
+	return filepath.Join(ConfigHome(), appName)
+}
+
+// DefaultConfig returns the path to the default configuration file of application: $XDG_CONFIG_HOME/<appName>/default.cfg
+func DefaultConfig(appName string) string {
+	return filepath.Join(ConfigDir(appName), "default.cfg")
+}
+
+// LegacyConfigDir returns the application configuration directory used by previous versions: $HOME/.config/<appName>
+func LegacyConfigDir(appName string) string {
+	return filepath.Join(os.Getenv("HOME"), ".config", appName)
+}
+
+// StateDir returns the directory for application state files (logs): $XDG_STATE_HOME/<appName>
+// or $HOME/.local/state/<appName> when XDG_STATE_HOME is not set.
+func StateDir(appName string) string {
+	return filepath.Join(xdgHome("XDG_STATE_HOME", ".local/state"), appName)
+}
+
+// DataDir returns the directory for application data files (user icon themes): $XDG_DATA_HOME/<appName>
+// or $HOME/.local/share/<appName> when XDG_DATA_HOME is not set.
+func DataDir(appName string) string {
+	return filepath.Join(xdgHome("XDG_DATA_HOME", ".local/share"), appName)
+}
+
+// RuntimeDir returns the directory for runtime files (locks and sockets) of application.
+// It is $XDG_RUNTIME_DIR/<appName> or <temp dir>/<appName>-<uid> when XDG_RUNTIME_DIR is not set.
+func RuntimeDir(appName string) string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", appName, os.Getuid()))
+}
+
+// DaemonConfig returns the default path to the daemon config file: $XDG_CONFIG_HOME/yandex-disk/config.cfg or
+// $HOME/.config/yandex-disk/config.cfg when it exists and the first one doesn't.
+func DaemonConfig() string {
+	conf := filepath.Join(ConfigHome(), "yandex-disk", "config.cfg")
+	if legacy := filepath.Join(os.Getenv("HOME"), ".config", "yandex-disk", "config.cfg"); NotExists(conf) && !NotExists(legacy) {
+		return legacy
+	}
+	return conf
+}
+
+// moveFile moves the file. It copies the file when it can't be renamed (e.g. to another file system).
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
+
+// MigrateFile moves the file and its format upgrade backups (<file>.v<version>.bak) from the legacy location to
+// the new path. Nothing is moved when the file already exists at the new path. The legacy directory is removed
+// when it becomes empty. It returns the paths of moved files.
+func MigrateFile(legacy, path string) ([]string, error) {
+	if filepath.Clean(legacy) == filepath.Clean(path) || !NotExists(path) || NotExists(legacy) {
+		return nil, nil
+	}
+	legacyDir, name := filepath.Split(legacy)
+	entries, err := os.ReadDir(legacyDir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{name}
+	for _, e := range entries {
+		if n := e.Name(); e.Type().IsRegular() && strings.HasPrefix(n, name+".v") && strings.HasSuffix(n, ".bak") {
+			files = append(files, n)
+		}
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	var moved []string
+	for n, file := range files {
+		to := filepath.Join(dir, file)
+		if n == 0 {
+			to = path
+		}
+		if !NotExists(to) {
+			continue // the backup of the same version is already there
+		}
+		if err := moveFile(filepath.Join(legacyDir, file), to); err != nil {
+			return moved, fmt.Errorf("can't move %s to %s: %v", file, dir, err)
+		}
+		moved = append(moved, to)
+	}
+	os.Remove(legacyDir) // it fails when the directory is not empty
+	return moved, nil
+}
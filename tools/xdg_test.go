@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXdgDirs(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_STATE_HOME", "")
+		t.Setenv("XDG_DATA_HOME", "relative/path") // relative paths are ignored
+		require.Equal(t, "/home/user/.config/testApp", ConfigDir("testApp"))
+		require.Equal(t, "/home/user/.local/state/testApp", StateDir("testApp"))
+		require.Equal(t, "/home/user/.local/share/testApp", DataDir("testApp"))
+		require.Equal(t, "/home/user/.config/yandex-disk/config.cfg", DaemonConfig())
+	})
+	t.Run("variables", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+		t.Setenv("XDG_STATE_HOME", "/xdg/state")
+		t.Setenv("XDG_DATA_HOME", "/xdg/data")
+		require.Equal(t, "/xdg/config/testApp", ConfigDir("testApp"))
+		require.Equal(t, "/home/user/.config/testApp", LegacyConfigDir("testApp"))
+		require.Equal(t, "/xdg/state/testApp", StateDir("testApp"))
+		require.Equal(t, "/xdg/data/testApp", DataDir("testApp"))
+		require.Equal(t, "/xdg/config/yandex-disk/config.cfg", DaemonConfig())
+	})
+	t.Run("legacy daemon config", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+		legacy := filepath.Join(home, ".config", "yandex-disk", "config.cfg")
+		require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0700))
+		require.NoError(t, os.WriteFile(legacy, nil, 0600))
+		require.Equal(t, legacy, DaemonConfig())
+	})
+}
+
+func TestRuntimeDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	require.Equal(t, "/run/user/1000/testApp", RuntimeDir("testApp"))
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	require.Contains(t, RuntimeDir("testApp"), "testApp-")
+}
+
+func TestMigrateFile(t *testing.T) {
+	legacy := filepath.Join(t.TempDir(), "legacy", "default.cfg")
+	path := filepath.Join(t.TempDir(), "new", "default.cfg")
+	moved, err := MigrateFile(legacy, path)
+	require.NoError(t, err)
+	require.Empty(t, moved)
+	legacyDir, dir := filepath.Dir(legacy), filepath.Dir(path)
+	require.NoError(t, os.MkdirAll(legacyDir, 0700))
+	for _, name := range []string{"default.cfg", "default.cfg.v0.bak", "work.cfg", "work.cfg.v0.bak"} {
+		require.NoError(t, os.WriteFile(filepath.Join(legacyDir, name), []byte("old"), 0600))
+	}
+	moved, err = MigrateFile(legacy, path)
+	require.NoError(t, err)
+	require.Equal(t, []string{path, path + ".v0.bak"}, moved)
+	require.True(t, NotExists(legacy))
+	require.FileExists(t, filepath.Join(legacyDir, "work.cfg")) // other files are not moved
+	require.FileExists(t, filepath.Join(legacyDir, "work.cfg.v0.bak"))
+	require.NoError(t, os.WriteFile(legacy, []byte("old"), 0600))
+	require.NoError(t, os.WriteFile(path, []byte("new"), 0600))
+	moved, err = MigrateFile(legacy, path)
+	require.NoError(t, err)
+	require.Empty(t, moved)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "new", string(data)) // existing file is not overwritten
+	require.FileExists(t, legacy)
+	moved, err = MigrateFile(filepath.Join(dir, "default.cfg"), path)
+	require.NoError(t, err)
+	require.Empty(t, moved)
+}
+
+func TestMoveFile(t *testing.T) {
+	from := filepath.Join(t.TempDir(), "from")
+	require.NoError(t, os.WriteFile(from, []byte("data"), 0600))
+	to := filepath.Join(t.TempDir(), "to")
+	require.NoError(t, moveFile(from, to))
+	require.True(t, NotExists(from))
+	require.Error(t, moveFile(from, to))
+}
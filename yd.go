@@ -6,18 +6,21 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"path"
-	"syscall"
-
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/slytomcat/systray"
+	"github.com/slytomcat/yd-go/hooks"
 	"github.com/slytomcat/yd-go/icons"
 	"github.com/slytomcat/yd-go/notify"
 	"github.com/slytomcat/yd-go/tools"
@@ -41,38 +44,77 @@ Copyleft 2017-%s Sly_tom_cat (slytomcat@mail.ru)
 	License: GPL v.3
 
 `
-	ydURL                = "https://disk.yandex.ru"
-	faqURL               = "https://github.com/slytomcat/yd-go/wiki/FAQ"
-	helpURL              = "https://github.com/slytomcat/yd-go/wiki/FAQ&SUPPORT"
-	donateUrl            = "https://github.com/slytomcat/yd-go/wiki/Donations"
-	lastLen              = 10
-	saveDelay            = 90 * time.Second // delay for saving configuration file after changes
+	repoURL   = "https://github.com/slytomcat/yd-go"
+	ydURL     = "https://disk.yandex.ru"
+	faqURL    = "https://github.com/slytomcat/yd-go/wiki/FAQ"
+	helpURL   = "https://github.com/slytomcat/yd-go/wiki/FAQ&SUPPORT"
+	donateUrl = "https://github.com/slytomcat/yd-go/wiki/Donations"
+	saveDelay = 90 * time.Second // delay for saving configuration file after changes
+	watchdog  = 60 * time.Second // systemd watchdog timeout for generated service unit
 )
 
 type indicator struct {
-	cfg        *tools.Config                          // app config
-	msg        func(message.Reference, ...any) string // msg is the Localization printer func
-	icon       *icons.Icon                            // icon helper
-	notifySend func(title, msg string)                // function to send notification, nil means that notifications are not available
-	log        *slog.Logger                           // logger
-	menu       *menu                                  // app menu
+	cfg         *tools.Config                          // app config
+	msg         func(message.Reference, ...any) string // msg is the Localization printer func
+	icon        *icons.Icon                            // icon helper
+	notifySend  func(m notify.Message)                 // function to send notification, nil in status bar mode
+	log         *slog.Logger                           // logger of UI component
+	iconLog     *slog.Logger                           // logger of icons component
+	logger      *tools.Logger                          // application logger
+	menu        *menu                                  // app menu
+	yd          *ydisk.YDisk                           // daemon helper
+	stat        string                                 // current daemon status
+	iconStat    string                                 // status of the current icon
+	status      string                                 // current status line (the same as in menu)
+	hooks       *hooks.Runner                          // user hooks runner
+	last        []string                               // last synchronized items from previous update
+	lastErr     string                                 // error message and path from previous update
+	sd          *tools.SdNotifier                      // systemd notifier
+	watchdog    *time.Ticker                           // systemd watchdog heartbeats ticker, nil when watchdog is not enabled
+	ready       bool                                   // readiness was reported to systemd
+	params      tools.Params                           // command line parameters
+	cfgChanges  <-chan []string                        // names of config fields changed by external edit, nil when the config file is not watched
+	profile     string                                 // name of the profile that yd belongs to, empty for the default profile
+	conf        string                                 // daemon config file path that yd is created for
+	queue       *notifyQueue                           // notification queue, nil means that notifications are not available
+	notifier    *notify.Manager                        // notifications manager, nil in status bar mode
+	quotaWarned bool                                   // the disk space usage is above the warning level
+	eta         etaEstimator                           // remaining synchronization time estimator
+	desktop     appearanceState                        // desktop appearance settings for auto theme and reduced motion
+	usage       int                                    // disk space usage in percents from the last update, -1 when it is not known
+	clipboard   tools.Clipboard                        // clipboard for copy actions, nil in status bar mode
+	clicks      chan bool                              // tray icon clicks: true for secondary activation, false for primary one
+	clickCfg    tools.ClickConfig                      // click actions that the click handlers are set for
 }
 
 type menu struct {
-	status      *systray.MenuItem          // menu item to show current status
-	size1       *systray.MenuItem          // menu item to show used/total sizes
-	size2       *systray.MenuItem          // menu item to show free anf trash sizes
-	last        *systray.MenuItem          // Sub-menu with last synchronized
-	lastMItem   [lastLen]*systray.MenuItem // last synchronized menu items
-	lastPath    [lastLen]string            // paths to last synchronized
-	start       *systray.MenuItem          // start daemon item
-	stop        *systray.MenuItem          // stop daemon item
+	status      *systray.MenuItem // menu item to show current status
+	size1       *systray.MenuItem // menu item to show used/total sizes
+	size2       *systray.MenuItem // menu item to show free anf trash sizes
+	last        *systray.MenuItem // Sub-menu with last synchronized
+	lastItems   []*lastEntry      // last synchronized items entries of the list that is not grouped by folders
+	lastGroups  []*lastGroup      // folders sub-menus of the list that is grouped by folders
+	lastCh      chan lastClick    // clicked action of last synchronized item
+	lastList    []string          // last synchronized items reported by daemon
+	start       *systray.MenuItem // start daemon item
+	stop        *systray.MenuItem // stop daemon item
 	out         *systray.MenuItem
+	copyOut     *systray.MenuItem // copy daemon output item
 	path        *systray.MenuItem
-	notes       *systray.MenuItem
-	theme       *systray.MenuItem
+	notesMenu   *systray.MenuItem   // Sub-menu with notifications settings
+	notes       *systray.MenuItem   // all notifications switch
+	noteEvents  []*systray.MenuItem // event notifications switches in order of notifyEvents
+	noteCh      chan int            // index of clicked event notifications switch
+	themeMenu   *systray.MenuItem   // Sub-menu with icon themes
+	themes      []*systray.MenuItem // theme menu items in order of themeNames
+	themeNames  []string            // names of themes shown in menu
+	themeCh     chan int            // index of clicked theme menu item
+	usageBar    *systray.MenuItem   // disk space usage bar switch
 	daemonStart *systray.MenuItem
 	daemonStop  *systray.MenuItem
+	profile     *systray.MenuItem   // Sub-menu with profiles
+	profiles    []*systray.MenuItem // profile menu items, the first one is the default profile
+	profileCh   chan int            // index of clicked profile menu item
 	site        *systray.MenuItem
 	help        *systray.MenuItem
 	about       *systray.MenuItem
@@ -90,23 +132,38 @@ func (i *indicator) makeMenu() {
 	i.menu.size2 = systray.AddMenuItem("", "")
 	systray.AddSeparator()
 	i.menu.last = systray.AddMenuItem(i.msg("Last synchronized"), "")
-	for j := range lastLen {
-		l := i.menu.last.AddSubMenuItem("", "")
-		l.Hide()
-		i.menu.lastMItem[j] = l
-	}
+	i.menu.lastCh = make(chan lastClick)
 	systray.AddSeparator()
 	i.menu.start = systray.AddMenuItem(i.msg("Start daemon"), "")
 	i.menu.stop = systray.AddMenuItem(i.msg("Stop daemon"), "")
 	systray.AddSeparator()
 	i.menu.out = systray.AddMenuItem(i.msg("Show daemon output"), "")
+	i.menu.copyOut = systray.AddMenuItem(i.msg("Copy daemon output"), "")
 	i.menu.path = systray.AddMenuItem(i.msg("Open Yandex.Disk folder"), "")
 	i.menu.site = systray.AddMenuItem(i.msg("Open Yandex.Disk in browser"), "")
 	setup := systray.AddMenuItem(i.msg("Settings"), "")
-	i.menu.theme = setup.AddSubMenuItemCheckbox(i.msg("Light theme"), "", i.cfg.GetTheme() == "light")
-	i.menu.notes = setup.AddSubMenuItemCheckbox(i.msg("Notifications"), "", i.cfg.GetNotifications())
+	i.menu.themeMenu = setup.AddSubMenuItem(i.msg("Theme"), "")
+	i.menu.themeCh = make(chan int)
+	i.updateThemes()
+	i.menu.usageBar = setup.AddSubMenuItemCheckbox(i.msg("Disk usage on icon"), "", i.cfg.GetUsageBar())
+	i.menu.notesMenu = setup.AddSubMenuItem(i.msg("Notifications"), "")
+	i.menu.notes = i.menu.notesMenu.AddSubMenuItemCheckbox(i.msg("Enabled"), "", i.cfg.GetNotifications())
+	i.menu.noteCh = make(chan int)
+	for n, e := range notifyEvents {
+		mi := i.menu.notesMenu.AddSubMenuItemCheckbox(i.msg(e.label), "", false)
+		go func() {
+			for range mi.ClickedCh {
+				i.menu.noteCh <- n
+			}
+		}()
+		i.menu.noteEvents = append(i.menu.noteEvents, mi)
+	}
+	i.updateNotifyEvents()
 	i.menu.daemonStart = setup.AddSubMenuItemCheckbox(i.msg("Start on start"), "", i.cfg.GetStartDaemon())
 	i.menu.daemonStop = setup.AddSubMenuItemCheckbox(i.msg("Stop on exit"), "", i.cfg.GetStopDaemon())
+	i.menu.profile = setup.AddSubMenuItem(i.msg("Profile"), "")
+	i.menu.profileCh = make(chan int)
+	i.updateProfiles()
 	systray.AddSeparator()
 	i.menu.help = systray.AddMenuItem(i.msg("Help"), "")
 	i.menu.about = systray.AddMenuItem(i.msg("About"), "")
@@ -117,19 +174,34 @@ func (i *indicator) makeMenu() {
 	i.menu.size1.Disable()
 	i.menu.size2.Disable()
 	i.menu.last.Disable()
+	i.menu.copyOut.Disable()
 	i.menu.start.Hide()
 	i.menu.stop.Hide()
-	if i.notifySend == nil { // disable all menu items that are dependant on notification service
-		i.menu.about.Disable()
-		i.menu.out.Disable()
-		i.menu.notes.Disable()
-		// add menu warning
-		systray.AddSeparator()
-		i.menu.warning = systray.AddMenuItem(i.msg("Notification service unavailable!"), notify.ToolTipMsg)
-	} else {
-		i.menu.warning = systray.AddMenuItem("", "")
+	i.menu.warning = systray.AddMenuItem(i.msg("Notification service unavailable!"), notify.ToolTipMsg)
+	i.updateNotifyBackend()
+}
+
+// notifyAvailable returns true when the notifications are shown by notification server or desktop portal
+func (i *indicator) notifyAvailable() bool {
+	return i.notifier != nil && i.notifier.Backend() != notify.BackendLog
+}
+
+// updateNotifyBackend enables the menu items that are dependant on notification service and hides the warning
+// when the notifications are available, otherwise it disables those items and shows the warning.
+func (i *indicator) updateNotifyBackend() {
+	if i.notifyAvailable() {
+		i.menu.about.Enable()
+		if i.stat != "none" {
+			i.menu.out.Enable()
+		}
+		i.menu.notesMenu.Enable()
 		i.menu.warning.Hide()
+		return
 	}
+	i.menu.about.Disable()
+	i.menu.out.Disable()
+	i.menu.notesMenu.Disable()
+	i.menu.warning.Show()
 }
 
 // SetupLocalization initializes translations
@@ -142,125 +214,600 @@ func SetupLocalization(logger *slog.Logger) *message.Printer {
 	return message.NewPrinter(message.MatchLanguage(lng))
 }
 
-func main() {
-	cfgPath, debug := tools.GetParams(appName, os.Args, version)
-	_, id := path.Split(cfgPath)
-	systray.SetID(fmt.Sprintf("%s_%s", appName, id))
-	systray.Run(func() {
-		defer systray.Quit() // it releases systray.Run in main()
-		log := tools.SetupLogger(debug, os.Stdout)
-		cfg, err := tools.NewConfig(cfgPath, saveDelay, log)
+// commands are the commands that can be passed via command line. When an instance with the same
+// configuration is already running, the command is handed over to it.
+var commands = map[string]bool{
+	"start":  true, // start daemon
+	"stop":   true, // stop daemon
+	"toggle": true, // start daemon when it is stopped or stop it otherwise
+	"open":   true, // open synchronized folder
+	"focus":  true, // show notification with current status
+}
+
+// localCommands are the commands that are performed by the launch itself and never handed over to the running instance
+var localCommands = map[string]func(params tools.Params) error{
+	"install-service": installService, // generate systemd user service unit
+	"config":          configCommand,  // validate, show or migrate the configuration file
+}
+
+// configCommand performs the configuration file subcommand: validate, show or migrate
+func configCommand(params tools.Params) error {
+	sub := ""
+	if len(params.Args) > 0 {
+		sub = params.Args[0]
+	}
+	switch sub {
+	case "validate":
+		cfg, version, err := tools.ReadConfig(params.Config, true)
 		if err != nil {
-			log.Error("config_error", "error", err)
-			os.Exit(1)
+			return err
+		}
+		if err := cfg.ApplyOverrides(params.Overrides); err != nil {
+			return err
 		}
-		defer cfg.Flush() // save config on exit if it was changed
-		i := &indicator{
-			cfg: cfg,
-			msg: SetupLocalization(log).Sprintf,
-			log: log,
+		if version < tools.ConfigVersion {
+			fmt.Printf("%s is valid, its format version %d can be upgraded to %d by 'config migrate' command\n", params.Config, version, tools.ConfigVersion)
+		} else {
+			fmt.Printf("%s is valid\n", params.Config)
+		}
+	case "show":
+		cfg, _, err := tools.ReadConfig(params.Config, false)
+		if err != nil {
+			return err
+		}
+		if err := cfg.ApplyOverrides(params.Overrides); err != nil {
+			return err
 		}
-		// create new YDisk instance
-		YD, err := ydisk.NewYDisk(i.cfg.Conf, i.log)
+		data, _ := json.MarshalIndent(cfg, "", "    ")
+		fmt.Println(string(data))
+		for _, s := range cfg.Settings() {
+			fmt.Printf("%s=%s (%s)\n", s.Field, s.Value, s.Source)
+		}
+	case "migrate":
+		backup, err := tools.MigrateConfig(params.Config)
 		if err != nil {
-			i.log.Error("daemon_initialization", "error", err)
+			return err
+		}
+		if backup == "" {
+			fmt.Printf("%s is already in the current format version %d\n", params.Config, tools.ConfigVersion)
+		} else {
+			fmt.Printf("%s is upgraded to format version %d, the original file is saved as %s\n", params.Config, tools.ConfigVersion, backup)
+		}
+	default:
+		return fmt.Errorf("unknown config subcommand '%s' (should be 'validate', 'show' or 'migrate')", sub)
+	}
+	return nil
+}
+
+// installService writes the systemd user service unit for the current executable and configuration file
+func installService(params tools.Params) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	name := appName + ".service"
+	if base := filepath.Base(params.Config); base != "default.cfg" {
+		name = appName + "-" + strings.TrimSuffix(base, filepath.Ext(base)) + ".service"
+	}
+	unitDir := filepath.Join(tools.ConfigHome(), "systemd", "user")
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		return err
+	}
+	unit := tools.ServiceUnit(appTitle, repoURL, watchdog, exe, "-config="+params.Config)
+	unitPath := filepath.Join(unitDir, name)
+	if err := os.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Service unit is written to %s\nActivate it by:\n\tsystemctl --user daemon-reload\n\tsystemctl --user enable --now %s\n", unitPath, name)
+	return nil
+}
+
+// lockInstance takes the single instance lock for configuration file. When another instance is already running,
+// it hands over the command to it or reports about running instance and exits.
+func lockInstance(params tools.Params) *tools.Instance {
+	runtimeDir := tools.RuntimeDir(appName)
+	inst, err := tools.NewInstance(runtimeDir, params.Config)
+	if err == nil {
+		return inst
+	}
+	if !errors.Is(err, tools.ErrAlreadyRunning) {
+		fmt.Fprintf(os.Stderr, "Single instance lock error: %v\n", err)
+		os.Exit(1)
+	}
+	if params.Command == "" {
+		fmt.Fprintf(os.Stderr, "%s is already running with configuration %s\n", appName, params.Config)
+		os.Exit(1)
+	}
+	if err := tools.SendCommand(runtimeDir, params.Config, params.Command); err != nil {
+		fmt.Fprintf(os.Stderr, "Command '%s' was not passed to running %s: %v\n", params.Command, appName, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+	return nil
+}
+
+// migrateConfig moves the default configuration file from the location used by previous versions. The files
+// specified by -config option are never moved. It is called under the single instance lock as the running
+// instance may still use the legacy file.
+func migrateConfig(params tools.Params) {
+	if filepath.Clean(params.Config) != tools.DefaultConfig(appName) {
+		return
+	}
+	legacy := filepath.Join(tools.LegacyConfigDir(appName), filepath.Base(params.Config))
+	moved, err := tools.MigrateFile(legacy, params.Config)
+	for _, path := range moved {
+		fmt.Fprintf(os.Stderr, "Configuration file is moved to %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration files migration error: %v\n", err)
+	}
+}
+
+func main() {
+	params := tools.GetParams(appName, os.Args, version)
+	if command, ok := localCommands[params.Command]; ok {
+		if err := command(params); err != nil {
+			fmt.Fprintf(os.Stderr, "Command '%s' error: %v\n", params.Command, err)
 			os.Exit(1)
 		}
-		defer YD.Close()
-		// handle starting/stopping daemon
-		if i.cfg.GetStartDaemon() {
-			go YD.Start()
+		return
+	}
+	if params.Command != "" && !commands[params.Command] {
+		fmt.Fprintf(os.Stderr, "Unknown command: '%s'\n", params.Command)
+		os.Exit(2)
+	}
+	if params.StatusBar != "" && !barModes[params.StatusBar] {
+		fmt.Fprintf(os.Stderr, "Unknown status bar mode: '%s'\n", params.StatusBar)
+		os.Exit(2)
+	}
+	inst := lockInstance(params)
+	defer inst.Close()
+	migrateConfig(params)
+	if params.Command != "" {
+		inst.Commands <- params.Command // perform the command after initialization
+	}
+	if params.StatusBar != "" {
+		runStatusBar(params, inst)
+		return
+	}
+	i := newIndicator(params)
+	i.setClickHandlers() // the handlers are used by tray icon registration in systray.Run
+	_, id := path.Split(params.Config)
+	systray.SetID(fmt.Sprintf("%s_%s", appName, id))
+	systray.Run(func() {
+		defer systray.Quit() // it releases systray.Run in main()
+		runIndicator(i, inst)
+	}, nil)
+}
+
+// newIndicator initializes the components that are common for the tray and status bar modes: logger,
+// configuration, localization, user hooks and YDisk. It also starts the daemon when it is configured.
+// Use close to release them on exit.
+func newIndicator(params tools.Params) *indicator {
+	logOut := os.Stdout
+	if params.StatusBar != "" {
+		logOut = os.Stderr // stdout is used for status bar output
+	}
+	bootLog := tools.SetupLogger(params.Debug, logOut) // logger for the configuration reading
+	cfg, err := tools.NewConfig(params.Config, saveDelay, bootLog)
+	if err != nil {
+		bootLog.Error("config_error", "error", err)
+		os.Exit(1)
+	}
+	if err := cfg.ApplyOverrides(params.Overrides); err != nil {
+		bootLog.Error("config_error", "error", err)
+		os.Exit(1)
+	}
+	logger, err := tools.NewLogger(logConfig(cfg.GetLog(), params), appName, logOut)
+	if err != nil {
+		bootLog.Error("logger_error", "error", err)
+		os.Exit(1)
+	}
+	cfgLog := logger.With("component", "config")
+	cfg.SetLogger(cfgLog)
+	for _, s := range cfg.Settings() {
+		cfgLog.Debug("config_setting", "field", s.Field, "value", s.Value, "source", s.Source)
+	}
+	log := logger.With("component", "ui")
+	hooksList, hooksLimit := cfg.GetHooks()
+	i := &indicator{
+		cfg:     cfg,
+		msg:     SetupLocalization(log).Sprintf,
+		log:     log,
+		iconLog: logger.With("component", "icons"),
+		logger:  logger,
+		hooks:   hooks.NewRunner(hooksList, hooksLimit, log),
+		sd:      tools.NewSdNotifier(),
+		params:  params,
+		usage:   -1,
+	}
+	if i.cfgChanges, err = cfg.Watch(); err != nil {
+		i.log.Warn("config_watch", "error", err)
+	}
+	if interval := i.sd.WatchdogInterval(); interval > 0 {
+		i.watchdog = time.NewTicker(interval)
+	}
+	// create new YDisk instance for the active profile
+	_, i.profile = cfg.GetProfiles()
+	i.conf = cfg.GetConf()
+	i.yd, err = ydisk.NewYDisk(i.conf, logger.With("component", "ydisk"))
+	if err != nil {
+		i.log.Error("daemon_initialization", "error", err)
+		os.Exit(1)
+	}
+	// handle starting daemon
+	if i.cfg.GetStartDaemon() {
+		go i.yd.Start()
+	}
+	return i
+}
+
+// close stops the daemon when it is configured, closes the YDisk, waits for running hooks and
+// saves the configuration if it was changed.
+func (i *indicator) close() {
+	_ = i.sd.Notify("STOPPING=1")
+	if i.watchdog != nil {
+		i.watchdog.Stop()
+	}
+	if i.cfg.GetStopDaemon() {
+		i.yd.Stop()
+	}
+	i.yd.Close()
+	i.hooks.Close()
+	i.cfg.Flush()
+	i.logger.Close()
+}
+
+// applyConfig applies the configuration fields changed by external edit of the configuration file
+func (i *indicator) applyConfig(fields []string) {
+	i.log.Info("config_reload", "changed", fields)
+	for _, field := range fields {
+		switch field {
+		case "Theme":
+			if i.icon != nil {
+				i.setTheme()
+			}
+		case "Animation":
+			if i.icon != nil {
+				i.updateAnimation()
+			}
+		case "Click":
+			if i.clicks != nil && clickHandlers(i.cfg.GetClick()) != clickHandlers(i.clickCfg) {
+				i.log.Warn("click_actions", "status", "restart_required")
+			}
+		case "Last":
+			if i.menu != nil {
+				i.updateLast(i.menu.lastList)
+			}
+		case "UsageBar":
+			if i.menu != nil {
+				setCheck(i.menu.usageBar, i.cfg.GetUsageBar())
+				i.updateUsageBar()
+			}
+		case "Notifications", "NotifyEvents":
+			i.updateNotifyEvents()
+		case "StartDaemon", "StopDaemon", "Conf", "Profiles", "Profile":
+			i.switchDaemon()
+			i.updateProfiles()
+		case "Hooks", "HooksLimit":
+			hooksList, hooksLimit := i.cfg.GetHooks()
+			old := i.hooks
+			i.hooks = hooks.NewRunner(hooksList, hooksLimit, i.log)
+			go old.Close() // wait for running hooks in background
+		case "Log":
+			if err := i.logger.Configure(logConfig(i.cfg.GetLog(), i.params)); err != nil {
+				i.log.Error("logger_error", "error", err)
+			}
 		}
-		defer func() {
-			if i.cfg.GetStopDaemon() {
-				YD.Stop()
+	}
+}
+
+// switchDaemon replaces the YDisk by the new one when the daemon configuration file of the active profile is changed.
+// The previous daemon is stopped when its profile is configured to stop the daemon.
+func (i *indicator) switchDaemon() {
+	_, profile := i.cfg.GetProfiles()
+	conf := i.cfg.GetConf()
+	if conf == i.conf {
+		i.profile = profile
+		return
+	}
+	yd, err := ydisk.NewYDisk(conf, i.logger.With("component", "ydisk"))
+	if err != nil {
+		i.log.Error("daemon_switch", "profile", profile, "conf", conf, "error", err)
+		return
+	}
+	i.log.Info("daemon_switch", "profile", profile, "conf", conf)
+	if old, ok := i.cfg.GetProfile(i.profile); !ok || old.StopDaemon {
+		i.yd.Stop() // the removed profile is considered as configured to stop the daemon
+	}
+	i.yd.Close()
+	i.yd, i.profile, i.conf = yd, profile, conf
+	i.last, i.lastErr = nil, ""
+	if i.cfg.GetStartDaemon() {
+		go i.yd.Start()
+	}
+}
+
+// updateProfiles updates the profile menu items and the daemon settings check marks of the active profile.
+// The profile sub-menu is hidden when there are no profiles except the default one.
+func (i *indicator) updateProfiles() {
+	if i.menu == nil {
+		return
+	}
+	setCheck(i.menu.daemonStart, i.cfg.GetStartDaemon())
+	setCheck(i.menu.daemonStop, i.cfg.GetStopDaemon())
+	names, active := i.cfg.GetProfiles()
+	names = append([]string{""}, names...)
+	for len(i.menu.profiles) < len(names) {
+		mi := i.menu.profile.AddSubMenuItemCheckbox("", "", false)
+		go func(n int) {
+			for range mi.ClickedCh {
+				i.menu.profileCh <- n
 			}
-		}()
-		// register interrupt signals chan
-		canceled := make(chan os.Signal, 1)
-		signal.Notify(canceled, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
-		// set systray title
-		systray.SetTitle(i.msg(appTitle))
-		// initialize icon helper
-		i.icon = icons.NewIcon(i.cfg.GetTheme(), systray.SetIcon)
-		defer i.icon.Close()
-		// Initialize notifications
-		if notifyHandler, err := notify.New(appName, i.icon.LogoIcon, false, -1); err != nil {
-			i.notifySend = nil
-			i.cfg.SetNotifications(false) // disable notifications into configuration
-			i.log.Warn("notifications", "status", "not_available", "error", err, "recommendation", notify.ToolTipMsg)
-		} else {
-			i.notifySend = func(title, msg string) {
-				i.log.Debug("sending_message", "title", title, "message", msg)
-				notifyHandler.Send(title, msg)
+		}(len(i.menu.profiles))
+		i.menu.profiles = append(i.menu.profiles, mi)
+	}
+	for n, mi := range i.menu.profiles {
+		if n >= len(names) {
+			mi.Hide()
+			continue
+		}
+		title := names[n]
+		if title == "" {
+			title = i.msg("Default")
+		}
+		mi.SetTitle(title)
+		setCheck(mi, names[n] == active)
+		mi.Show()
+	}
+	if len(names) > 1 {
+		i.menu.profile.Show()
+	} else {
+		i.menu.profile.Hide()
+	}
+}
+
+// handleProfileClick activates the profile of clicked menu item and switches the daemon to its configuration
+func (i *indicator) handleProfileClick(n int) {
+	names, _ := i.cfg.GetProfiles()
+	name := ""
+	if n > 0 && n <= len(names) {
+		name = names[n-1]
+	}
+	if err := i.cfg.SetProfile(name); err != nil {
+		i.log.Error("profile", "error", err)
+	}
+	i.switchDaemon()
+	i.updateProfiles()
+}
+
+// logConfig applies the -debug option and the status bar mode to the configured logging settings
+func logConfig(lc tools.LogConfig, params tools.Params) tools.LogConfig {
+	if params.Debug {
+		lc.Level = "debug"
+	}
+	if params.StatusBar != "" && (lc.Output == "" || lc.Output == "stdout") {
+		lc.Output = "stderr" // stdout is used for status bar output
+	}
+	return lc
+}
+
+// heartbeat returns the channel of systemd watchdog ticker or nil channel when watchdog is not enabled
+func (i *indicator) heartbeat() <-chan time.Time {
+	if i.watchdog == nil {
+		return nil
+	}
+	return i.watchdog.C
+}
+
+// reportStatus sends the current status line to systemd. The first call also reports the service readiness.
+func (i *indicator) reportStatus() {
+	state := []string{"STATUS=" + i.msg("Status: %s", i.status)}
+	if !i.ready {
+		state = append(state, "READY=1")
+		i.ready = true
+	}
+	if err := i.sd.Notify(state...); err != nil {
+		i.log.Debug("sd_notify", "error", err)
+	}
+}
+
+// runIndicator runs the tray icon with menu and handles the UI events until exit is requested
+func runIndicator(i *indicator, inst *tools.Instance) {
+	defer i.close()
+	// register interrupt signals chan
+	canceled := make(chan os.Signal, 1)
+	signal.Notify(canceled, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
+	// set systray title
+	systray.SetTitle(i.msg(appTitle))
+	// initialize icon helper
+	i.icon = icons.NewIcon(i.cfg.GetTheme(), systray.SetIcon)
+	defer i.icon.Close()
+	themesDir := filepath.Join(tools.DataDir(appName), "icons")
+	if err := i.icon.LoadThemes(themesDir); err != nil {
+		i.iconLog.Warn("themes", "dir", themesDir, "error", err)
+	}
+	var desktop <-chan appearanceState // desktop appearance changes, nil when the settings are not available
+	if a, state, err := newAppearance(); err == nil {
+		i.desktop, desktop = state, a.changes
+		defer a.close()
+		i.log.Debug("appearance", "scheme", state.scheme, "reduced_motion", state.reducedMotion)
+	} else {
+		i.log.Debug("appearance", "status", "not_available", "error", err)
+	}
+	i.setTheme() // the configured theme can be one of user themes or auto theme
+	i.updateAnimation()
+	// Initialize notifications
+	notifyLog := i.logger.With("component", "notify")
+	i.notifier = notify.NewManager(appName, i.icon.LogoIcon, notifyLog)
+	defer i.notifier.Close()
+	i.notifySend = func(m notify.Message) {
+		notifyLog.Debug("sending_message", "title", m.Title, "message", m.Body, "actions", len(m.Actions), "urgency", m.Urgency)
+		i.notifier.SendMessage(m)
+	}
+	i.queue = newNotifyQueue(i.handleNotifications, i.notifySummary, notifyWindow, notifyPeriod)
+	defer i.queue.close()
+	i.clipboard = tools.NewClipboard()
+	i.log.Debug("clipboard", "backend", i.clipboard.Name())
+	// Initialize systray menu
+	i.makeMenu()
+	// Start events handler
+	i.log.Debug("ui_event_handler", "status", "started")
+	defer i.log.Debug("ui_event_handler", "status", "exited")
+	for {
+		select {
+		case c := <-i.menu.lastCh:
+			i.handleLastClick(c)
+		case <-i.menu.start.ClickedCh:
+			go i.yd.Start()
+		case <-i.menu.stop.ClickedCh:
+			go i.yd.Stop()
+		case <-i.menu.out.ClickedCh:
+			i.showOutput(i.yd)
+		case <-i.menu.copyOut.ClickedCh:
+			i.copyText(i.yd.Output(), i.msg("Daemon output copied to clipboard"))
+		case <-i.menu.path.ClickedCh:
+			i.openPath(i.yd.Path)
+		case <-i.menu.site.ClickedCh:
+			i.openPath(ydURL)
+		case n := <-i.menu.themeCh:
+			i.handleThemeClick(n)
+		case secondary := <-i.clicks:
+			i.handleIconClick(secondary)
+		case <-i.menu.usageBar.ClickedCh:
+			i.cfg.SetUsageBar(handleCheck(i.menu.usageBar))
+			i.updateUsageBar()
+		case <-i.menu.notes.ClickedCh:
+			i.cfg.SetNotifications(handleCheck(i.menu.notes))
+			i.updateNotifyEvents()
+		case n := <-i.menu.noteCh:
+			i.cfg.SetNotifyEvent(notifyEvents[n].event, handleCheck(i.menu.noteEvents[n]))
+		case <-i.menu.daemonStart.ClickedCh:
+			i.cfg.SetStartDaemon(handleCheck(i.menu.daemonStart))
+		case <-i.menu.daemonStop.ClickedCh:
+			i.cfg.SetStopDaemon(handleCheck(i.menu.daemonStop))
+		case n := <-i.menu.profileCh:
+			i.handleProfileClick(n)
+		case <-i.menu.help.ClickedCh:
+			i.openPath(helpURL)
+		case <-i.menu.about.ClickedCh:
+			i.notifySend(notify.Message{Title: i.msg(appTitle), Body: i.msg(about, version, time.Now().Format("2006")), Urgency: notify.UrgencyNormal})
+		case <-i.menu.donate.ClickedCh:
+			i.openPath(donateUrl)
+		case state := <-desktop:
+			i.log.Debug("appearance", "scheme", state.scheme, "reduced_motion", state.reducedMotion)
+			schemeChanged := state.scheme != i.desktop.scheme
+			i.desktop = state
+			if schemeChanged && i.cfg.GetTheme() == autoTheme {
+				i.setTheme()
 			}
-			defer notifyHandler.Close()
+			i.updateAnimation()
+		case backend := <-i.notifier.Changes(): // notification service appeared or disappeared
+			i.log.Info("notifications", "backend", backend)
+			i.updateNotifyBackend()
+		case <-i.menu.warning.ClickedCh:
+			i.openPath(faqURL)
+		case yds := <-i.yd.Changes: // YDisk change event
+			i.handleUpdate(&yds, i.yd.Path)
+		case cmd := <-inst.Commands: // command from another launch
+			i.handleCommand(cmd)
+		case fields := <-i.cfgChanges: // external change of configuration file
+			i.applyConfig(fields)
+		case <-i.heartbeat(): // systemd watchdog heartbeat
+			_ = i.sd.Notify("WATCHDOG=1")
+		case sig := <-canceled: // SIGINT or SIGTERM signal received
+			fmt.Println() // to leave ^C on previous line
+			i.log.Warn("exit", "signal", sig)
+			return
+		case <-i.menu.quit.ClickedCh:
+			i.log.Debug("exit", "status", "requested")
+			return
+		}
+	}
+}
+
+// handleCommand performs the command received from command line or from another launch with the same configuration
+func (i *indicator) handleCommand(cmd string) {
+	i.log.Debug("command", "received", cmd)
+	switch cmd {
+	case "start":
+		go i.yd.Start()
+	case "stop":
+		go i.yd.Stop()
+	case "toggle":
+		i.toggleDaemon()
+	case "open":
+		i.openPath(i.yd.Path)
+	case "focus":
+		if i.notifySend != nil {
+			i.notifySend(notify.Message{Title: i.msg(appTitle), Body: i.msg("Status: %s", i.status), Urgency: notify.UrgencyNormal, Transient: true})
 		}
-		// Initialize systray menu
-		i.makeMenu()
-		// Start events handler
-		i.log.Debug("ui_event_handler", "status", "started")
-		defer i.log.Debug("ui_event_handler", "status", "exited")
-		for {
+	default:
+		i.log.Warn("command", "unknown", cmd)
+	}
+}
+
+// setClickHandlers sets the tray icon click handlers for the configured click actions. The handlers are set only
+// for the actions other than menu. The secondary handler also receives the right click and any handler clears
+// the ItemIsMenu property of tray icon, so the secondary handler is not set when the primary one is set: the right
+// click without handler shows the menu. The handlers are used on tray icon registration, so the change between
+// menu and other actions requires restart.
+func (i *indicator) setClickHandlers() {
+	i.clicks = make(chan bool, 1)
+	i.clickCfg = i.cfg.GetClick()
+	handlers := clickHandlers(i.clickCfg)
+	click := func(secondary bool) func() {
+		return func() {
 			select {
-			case <-i.menu.lastMItem[0].ClickedCh:
-				i.openPath(i.menu.lastPath[0])
-			case <-i.menu.lastMItem[1].ClickedCh:
-				i.openPath(i.menu.lastPath[1])
-			case <-i.menu.lastMItem[2].ClickedCh:
-				i.openPath(i.menu.lastPath[2])
-			case <-i.menu.lastMItem[3].ClickedCh:
-				i.openPath(i.menu.lastPath[3])
-			case <-i.menu.lastMItem[4].ClickedCh:
-				i.openPath(i.menu.lastPath[4])
-			case <-i.menu.lastMItem[5].ClickedCh:
-				i.openPath(i.menu.lastPath[5])
-			case <-i.menu.lastMItem[6].ClickedCh:
-				i.openPath(i.menu.lastPath[6])
-			case <-i.menu.lastMItem[7].ClickedCh:
-				i.openPath(i.menu.lastPath[7])
-			case <-i.menu.lastMItem[8].ClickedCh:
-				i.openPath(i.menu.lastPath[8])
-			case <-i.menu.lastMItem[9].ClickedCh:
-				i.openPath(i.menu.lastPath[9])
-			case <-i.menu.start.ClickedCh:
-				go YD.Start()
-			case <-i.menu.stop.ClickedCh:
-				go YD.Stop()
-			case <-i.menu.out.ClickedCh:
-				i.notifySend(i.msg("Yandex.Disk daemon output"), YD.Output())
-			case <-i.menu.path.ClickedCh:
-				i.openPath(YD.Path)
-			case <-i.menu.site.ClickedCh:
-				i.openPath(ydURL)
-			case <-i.menu.theme.ClickedCh:
-				i.cfg.SetTheme(i.handleThemeClick(i.menu.theme))
-			case <-i.menu.notes.ClickedCh:
-				i.cfg.SetNotifications(handleCheck(i.menu.notes))
-			case <-i.menu.daemonStart.ClickedCh:
-				i.cfg.SetStartDaemon(handleCheck(i.menu.daemonStart))
-			case <-i.menu.daemonStop.ClickedCh:
-				i.cfg.SetStopDaemon(handleCheck(i.menu.daemonStop))
-			case <-i.menu.help.ClickedCh:
-				i.openPath(helpURL)
-			case <-i.menu.about.ClickedCh:
-				i.notifySend(i.msg(appTitle), i.msg(about, version, time.Now().Format("2006")))
-			case <-i.menu.donate.ClickedCh:
-				i.openPath(donateUrl)
-			case <-i.menu.warning.ClickedCh:
-				i.openPath(faqURL)
-			case yds := <-YD.Changes: // YDisk change event
-				i.handleUpdate(&yds, YD.Path)
-			case sig := <-canceled: // SIGINT or SIGTERM signal received
-				fmt.Println() // to leave ^C on previous line
-				i.log.Warn("exit", "signal", sig)
-				return
-			case <-i.menu.quit.ClickedCh:
-				i.log.Debug("exit", "status", "requested")
-				return
+			case i.clicks <- secondary:
+			default: // the previous click is not handled yet
 			}
 		}
-	}, nil)
+	}
+	if handlers[0] {
+		systray.SetOnTapped(click(false))
+	}
+	if handlers[1] {
+		systray.SetOnSecondaryTapped(click(true))
+	}
+}
+
+// clickHandlers returns whether the primary and the secondary clicks need the handlers. The secondary action
+// is ignored when the primary click has the handler as one click has to show the menu.
+func clickHandlers(cc tools.ClickConfig) [2]bool {
+	primary := tools.ClickAction(cc.Primary) != tools.ClickMenu
+	return [2]bool{primary, !primary && tools.ClickAction(cc.Secondary) != tools.ClickMenu}
+}
+
+// handleIconClick performs the currently configured action of the tray icon click
+func (i *indicator) handleIconClick(secondary bool) {
+	cc := i.cfg.GetClick()
+	action := tools.ClickAction(cc.Primary)
+	if secondary {
+		action = tools.ClickAction(cc.Secondary)
+	}
+	i.log.Debug("click", "secondary", secondary, "action", action)
+	switch action {
+	case tools.ClickOpen:
+		i.openPath(i.yd.Path)
+	case tools.ClickToggle:
+		i.toggleDaemon()
+	case tools.ClickOutput:
+		i.showOutput(i.yd)
+	case tools.ClickSite:
+		i.openPath(ydURL)
+	default: // the menu can't be shown by the click handler that is set for the previous configuration
+		i.log.Warn("click_actions", "action", action, "status", "restart_required")
+	}
+}
+
+// toggleDaemon starts the daemon when it is stopped and stops it otherwise
+func (i *indicator) toggleDaemon() {
+	if i.stat == "none" {
+		go i.yd.Start()
+	} else {
+		go i.yd.Stop()
+	}
 }
 
 func (i *indicator) openPath(path string) {
@@ -269,6 +816,51 @@ func (i *indicator) openPath(path string) {
 	}
 }
 
+// showInFolder shows the file highlighted in its folder by the file manager. It is performed in background as
+// the file manager can be started by D-BUS activation.
+func (i *indicator) showInFolder(path string) {
+	go func() {
+		if err := tools.ShowInFolder(path); err != nil {
+			i.log.Error("show_in_folder", "path", path, "error", err)
+		}
+	}()
+}
+
+// canCopy returns true when there is a clipboard tool for copy actions
+func (i *indicator) canCopy() bool {
+	return i.clipboard != nil && i.clipboard.Name() != tools.NoClipboard
+}
+
+// copyText copies the text to the clipboard and confirms the copy by notification with the message
+func (i *indicator) copyText(text, message string) {
+	if err := i.clipboard.Copy(text); err != nil {
+		i.log.Error("copy", "clipboard", i.clipboard.Name(), "error", err)
+		return
+	}
+	i.log.Debug("copy", "clipboard", i.clipboard.Name(), "length", len(text))
+	if i.notifySend != nil {
+		i.notifySend(notify.Message{Title: i.msg(appTitle), Body: message, Urgency: notify.UrgencyLow, Timeout: eventTimeout, Transient: true})
+	}
+}
+
+// setCheck sets the check mark of menu item
+func setCheck(mi *systray.MenuItem, checked bool) {
+	if checked {
+		mi.Check()
+	} else {
+		mi.Uncheck()
+	}
+}
+
+// setEnabled enables or disables the menu item
+func setEnabled(mi *systray.MenuItem, enabled bool) {
+	if enabled {
+		mi.Enable()
+	} else {
+		mi.Disable()
+	}
+}
+
 func handleCheck(mi *systray.MenuItem) bool {
 	if mi.Checked() {
 		mi.Uncheck()
@@ -278,14 +870,90 @@ func handleCheck(mi *systray.MenuItem) bool {
 	return true
 }
 
-func (i *indicator) handleThemeClick(mi *systray.MenuItem) (theme string) {
-	if handleCheck(mi) {
-		theme = "light"
+// updateAnimation applies the busy icon animation settings. The desktop setting is used in auto reduced motion mode.
+func (i *indicator) updateAnimation() {
+	a := i.cfg.GetAnimation()
+	i.icon.SetAnimation(a.Interval(), a.Frames, a.Reduced(i.desktop.reducedMotion))
+}
+
+// updateUsageBar shows the disk space usage on the idle icon when it is switched on
+func (i *indicator) updateUsageBar() {
+	if i.cfg.GetUsageBar() {
+		i.icon.SetUsage(i.usage)
+	} else {
+		i.icon.SetUsage(-1)
+	}
+}
+
+// setTheme sets the configured icons theme. The current theme is kept when the configured one is not available.
+func (i *indicator) setTheme() {
+	if err := i.icon.SetTheme(i.iconTheme(i.cfg.GetTheme())); err != nil {
+		i.log.Warn("theme", "error", err)
+	}
+	i.updateThemes()
+}
+
+// iconTheme returns the icons theme for the configured theme. The auto theme is resolved by the desktop color scheme.
+func (i *indicator) iconTheme(theme string) string {
+	if theme == autoTheme {
+		return schemeTheme(i.desktop.scheme)
+	}
+	return theme
+}
+
+// updateThemes updates the theme menu items and the check mark of configured theme
+func (i *indicator) updateThemes() {
+	if i.menu == nil || i.icon == nil {
+		return
+	}
+	names, theme := append([]string{autoTheme}, i.icon.Themes()...), i.cfg.GetTheme()
+	for len(i.menu.themes) < len(names) {
+		mi := i.menu.themeMenu.AddSubMenuItemCheckbox("", "", false)
+		go func(n int) {
+			for range mi.ClickedCh {
+				i.menu.themeCh <- n
+			}
+		}(len(i.menu.themes))
+		i.menu.themes = append(i.menu.themes, mi)
+	}
+	for n, mi := range i.menu.themes {
+		if n >= len(names) {
+			mi.Hide()
+			continue
+		}
+		mi.SetTitle(i.themeTitle(names[n]))
+		setCheck(mi, names[n] == theme)
+		mi.Show()
+	}
+	i.menu.themeNames = names
+}
+
+// themeTitle returns the menu title of theme: localized name of built-in theme or the name of user theme
+func (i *indicator) themeTitle(theme string) string {
+	switch theme {
+	case autoTheme:
+		return i.msg("Automatic")
+	case "dark":
+		return i.msg("Dark")
+	case "light":
+		return i.msg("Light")
+	}
+	return theme
+}
+
+// handleThemeClick sets the theme of clicked menu item
+func (i *indicator) handleThemeClick(n int) {
+	if n >= len(i.menu.themeNames) {
+		return
+	}
+	theme := i.menu.themeNames[n]
+	i.iconLog.Debug("theme", "set", theme)
+	if err := i.icon.SetTheme(i.iconTheme(theme)); err != nil {
+		i.log.Error("theme", "error", err)
 	} else {
-		theme = "dark"
+		i.cfg.SetTheme(theme)
 	}
-	i.icon.SetTheme(theme)
-	return
+	i.updateThemes()
 }
 
 // joinNonEmpty joins non-empty strings with space and returns the result.
@@ -306,84 +974,277 @@ func joinNonEmpty(items ...string) string {
 
 // handleUpdate updates the indicator icon, menu state, and sends notifications if they are enabled.
 func (i *indicator) handleUpdate(yds *ydisk.YDvals, path string) {
-	st := joinNonEmpty(i.msg(yds.Stat), yds.Prog, yds.Err, tools.MakeTitle(yds.ErrP, 30))
+	st := statusLine(i.msg, yds)
+	i.stat, i.status = yds.Stat, st
+	i.reportStatus()
 	i.menu.status.SetTitle(i.msg("Status: %s", st))
-	i.menu.size1.SetTitle(i.msg("Used: %s/%s", yds.Used, yds.Total))
-	i.menu.size2.SetTitle(i.msg("Free: %s Trash: %s", yds.Free, yds.Trash))
+	used, free := sizeLines(i.msg, yds)
+	i.menu.size1.SetTitle(used)
+	i.menu.size2.SetTitle(free)
+	systray.SetTooltip(tooltip(i.msg, yds, i.eta.update(yds.Prog, time.Now())))
 	if yds.ChLast { // last synchronized list changed
-		for l := range lastLen {
-			if l < len(yds.Last) {
-				p := yds.Last[l]
-				i.menu.lastPath[l] = filepath.Join(path, p)
-				i.menu.lastMItem[l].SetTitle(tools.MakeTitle(p, 40))
-				if tools.NotExists(i.menu.lastPath[l]) {
-					i.menu.lastMItem[l].Disable()
-				} else {
-					i.menu.lastMItem[l].Enable()
-				}
-				i.menu.lastMItem[l].Show() // show list items
-			} else {
-				i.menu.lastMItem[l].Hide() // hide the rest of list
-			}
-		}
-		if len(yds.Last) == 0 {
-			i.menu.last.Disable()
-		} else {
-			i.menu.last.Enable()
-		}
-		i.menu.last.Show() // to update parent item view
+		i.updateLast(yds.Last)
 	}
 	yds.Stat = index2Busy(yds.Stat) // index and busy statuses are equal in terms of icons and notifications
 	yds.Prev = index2Busy(yds.Prev)
+	event := statusEvent(yds)
+	status, known := ydisk.ParseStatus(yds.Stat, yds.Err)
+	if !known && yds.Stat != yds.Prev {
+		i.log.Warn("daemon_status", "unknown", yds.Stat)
+	}
+	if s := iconStatus(status); s != i.iconStat { // the auth icon depends on error, so it can change with same status
+		i.iconStat = s
+		i.icon.Set(s)
+	}
+	i.icon.SetProgress(yds.Progress())
+	i.usage = yds.UsedPercent()
+	i.updateUsageBar()
 	if yds.Stat != yds.Prev { // status changed
-		// change indicator icon
-		i.icon.Set(none2Paused(yds.Stat)) // index were converted to busy earlier
 		// handle Start/Stop menu items
 		if yds.Stat == "none" || yds.Prev == "none" || yds.Prev == "unknown" {
 			if yds.Stat == "none" {
 				i.menu.start.Show()
 				i.menu.stop.Hide()
 				i.menu.out.Disable()
+				i.menu.copyOut.Disable()
 			} else {
 				i.menu.stop.Show()
 				i.menu.start.Hide()
-				if i.notifySend != nil {
+				if i.notifyAvailable() {
 					i.menu.out.Enable()
 				}
+				setEnabled(i.menu.copyOut, i.canCopy())
 			}
 		}
-		if i.cfg.GetNotifications() && i.notifySend != nil {
-			go i.handleNotifications(yds)
+	}
+	var items []string // new synchronized items
+	if yds.ChLast && yds.Prev != "unknown" {
+		items = newItems(i.last, yds.Last)
+	}
+	if event != "" {
+		i.notify(notification{event: event, yds: *yds, items: items, yd: i.yd})
+	}
+	if yds.Err != "" && yds.Err+yds.ErrP != i.lastErr {
+		i.notify(notification{event: hooks.Error, yds: *yds, yd: i.yd})
+	}
+	if len(items) > 0 {
+		i.notify(notification{event: hooks.NewItems, yds: *yds, items: items, yd: i.yd})
+	}
+	if usage := yds.UsedPercent(); usage >= 0 {
+		if !i.quotaWarned && usage >= quotaLevel {
+			i.notify(notification{event: tools.QuotaWarning, yds: *yds, yd: i.yd})
 		}
+		i.quotaWarned = usage >= quotaLevel
 	}
+	i.handleHooks(event, yds, path)
 	i.log.Debug("ui_change", "status", "handled", "last", len(yds.Last))
 }
 
-// index2Busy converts index to busy
-func index2Busy(status string) string {
-	if status == "index" {
-		return "busy"
+// handleHooks detects the events for user hooks and runs the hooks
+func (i *indicator) handleHooks(event string, yds *ydisk.YDvals, path string) {
+	e := hooks.Event{
+		Prev:    yds.Prev,
+		Status:  yds.Stat,
+		Err:     yds.Err,
+		ErrPath: yds.ErrP,
+		SyncDir: path,
+	}
+	if event != "" {
+		e.Kind = event
+		i.hooks.Run(e)
+	}
+	if errKey := yds.Err + yds.ErrP; errKey != i.lastErr {
+		i.lastErr = errKey
+		if yds.Err != "" {
+			e.Kind = hooks.Error
+			i.hooks.Run(e)
+		}
+	}
+	if yds.ChLast {
+		if items := newItems(i.last, yds.Last); len(items) > 0 && yds.Prev != "unknown" {
+			e.Kind = hooks.NewItems
+			e.Items = items
+			i.hooks.Run(e)
+		}
+		i.last = append(i.last[:0], yds.Last...)
 	}
-	return status
 }
 
-// none2Paused converts none to paused
-func none2Paused(status string) string {
-	if status == "none" {
-		return "paused"
+// newItems returns the items from cur which are not in prev
+func newItems(prev, cur []string) []string {
+	var res []string
+	for _, c := range cur {
+		if !slices.Contains(prev, c) {
+			res = append(res, c)
+		}
 	}
-	return status
+	return res
 }
 
-func (i *indicator) handleNotifications(yds *ydisk.YDvals) {
+// statusEvent returns the kind of the status transition event or empty string when status is not changed.
+// Index status have to be converted to busy before the call.
+func statusEvent(yds *ydisk.YDvals) string {
 	switch {
+	case yds.Stat == yds.Prev:
+		return ""
 	case yds.Stat == "none" && yds.Prev != "unknown":
-		i.notifySend(i.msg(appTitle), i.msg("Daemon stopped"))
+		return hooks.DaemonStopped
 	case yds.Prev == "none":
-		i.notifySend(i.msg(appTitle), i.msg("Daemon started"))
+		return hooks.DaemonStarted
 	case yds.Prev != "busy" && yds.Stat == "busy":
-		i.notifySend(i.msg(appTitle), i.msg("Synchronization started"))
+		return hooks.SyncStarted
 	case yds.Prev == "busy" && yds.Stat != "busy":
-		i.notifySend(i.msg(appTitle), i.msg("Synchronization finished"))
+		return hooks.SyncFinished
+	}
+	return ""
+}
+
+// index2Busy converts index to busy
+func index2Busy(status string) string {
+	if status == "index" {
+		return "busy"
 	}
+	return status
+}
+
+// iconStatus returns the icon status for the indicator status. Stopped daemon is displayed with paused icon.
+func iconStatus(status ydisk.Status) string {
+	if status == ydisk.StatusStopped {
+		return string(ydisk.StatusPaused)
+	}
+	return string(status)
+}
+
+// eventTimeout is the display time (in milliseconds) of the notifications about the regular status changes
+const eventTimeout = 5000
+
+// quotaLevel is the used disk space in percents that triggers the quota warning
+const quotaLevel = 90
+
+// notifyEvents are the events with switchable notifications in order of Settings/Notifications menu
+var notifyEvents = []notifyEvent{
+	{hooks.DaemonStarted, "Daemon started"},
+	{hooks.DaemonStopped, "Daemon stopped"},
+	{hooks.SyncStarted, "Synchronization started"},
+	{hooks.SyncFinished, "Synchronization finished"},
+	{hooks.Error, "Errors"},
+	{tools.QuotaWarning, "Disk space warnings"},
+	{hooks.NewItems, "New files"},
+}
+
+// notifyEvent is the event with switchable notifications and its menu item label
+type notifyEvent struct {
+	event string
+	label string
+}
+
+// notification is the event to notify about
+type notification struct {
+	event string       // event kind
+	yds   ydisk.YDvals // daemon values at the event
+	items []string     // new synchronized items for the NewItems event
+	yd    *ydisk.YDisk // daemon that reported the event
+}
+
+// updateNotifyEvents updates the check marks of notifications settings menu items.
+// The event items are disabled when all notifications are switched off.
+func (i *indicator) updateNotifyEvents() {
+	if i.menu == nil {
+		return
+	}
+	enabled := i.cfg.GetNotifications()
+	setCheck(i.menu.notes, enabled)
+	for n, e := range notifyEvents {
+		mi := i.menu.noteEvents[n]
+		setCheck(mi, i.cfg.GetNotifyEvent(e.event))
+		if enabled {
+			mi.Enable()
+		} else {
+			mi.Disable()
+		}
+	}
+}
+
+// notify puts the notification about the event into the notification queue when notifications about the event
+// are switched on
+func (i *indicator) notify(n notification) {
+	if i.queue != nil && i.cfg.GetNotifyEvent(n.event) && !i.queue.push(n) {
+		i.log.Warn("notification", "dropped", n.event)
+	}
+}
+
+// notifySummary sends the summary of notifications that were suppressed by the rate limit
+func (i *indicator) notifySummary(event string, count, files int) {
+	m := notify.Message{Title: i.msg(appTitle), Urgency: notify.UrgencyLow, Timeout: eventTimeout, Transient: true}
+	switch event {
+	case hooks.SyncFinished:
+		m.Body = i.msg("Synchronized %d times in the last minute, %d files", count, files)
+	case hooks.NewItems:
+		m.Body = i.msg("%d new files synchronized in the last minute", files)
+	default:
+		idx := slices.IndexFunc(notifyEvents, func(e notifyEvent) bool { return e.event == event })
+		if idx < 0 {
+			return
+		}
+		m.Body = i.msg("%s: %d times in the last minute", i.msg(notifyEvents[idx].label), count)
+	}
+	i.notifySend(m)
+}
+
+// handleNotifications sends the notification about the event. The notification actions are performed
+// with the daemon that reported the event.
+func (i *indicator) handleNotifications(n notification) {
+	yds, yd := n.yds, n.yd
+	m := notify.Message{Title: i.msg(appTitle), Urgency: notify.UrgencyLow, Timeout: eventTimeout, Transient: true}
+	switch n.event {
+	case hooks.DaemonStopped:
+		m.Body, m.Urgency, m.Timeout, m.Transient = i.msg("Daemon stopped"), notify.UrgencyNormal, 0, false
+		m.Icon = i.icon.StatusIcon("paused")
+		m.Actions = []notify.Action{
+			{Key: "start", Label: i.msg("Start daemon"), Handler: func() { yd.Start() }},
+			{Key: "output", Label: i.msg("Show output"), Handler: func() { i.showOutput(yd) }},
+		}
+	case hooks.DaemonStarted:
+		m.Body = i.msg("Daemon started")
+	case hooks.SyncStarted:
+		m.Body, m.Category, m.Icon = i.msg("Synchronization started"), "transfer", i.icon.StatusIcon("busy")
+	case hooks.SyncFinished:
+		m.Body, m.Category, m.Icon = i.msg("Synchronization finished"), "transfer.complete", i.icon.StatusIcon("idle")
+		m.Actions = []notify.Action{{Key: "folder", Label: i.msg("Open folder"), Handler: func() { i.openPath(yd.Path) }}}
+		if len(yds.Last) > 0 {
+			file := filepath.Join(yd.Path, yds.Last[0])
+			m.Actions = append(m.Actions, notify.Action{Key: "file", Label: i.msg("Open file"), Handler: func() { i.openPath(file) }})
+		}
+	case hooks.Error:
+		m.Body = joinNonEmpty(i.msg("Synchronization error:"), yds.Err, yds.ErrP)
+		m.Urgency, m.Timeout, m.Transient = notify.UrgencyNormal, 0, false
+		if ydisk.IsAuthError(yds.Err) {
+			m.Urgency = notify.UrgencyCritical
+		}
+		m.Category, m.Icon = "transfer.error", i.icon.StatusIcon("error")
+		m.Actions = []notify.Action{{Key: "output", Label: i.msg("Show output"), Handler: func() { i.showOutput(yd) }}}
+		if yds.ErrP != "" {
+			file := filepath.Join(yd.Path, yds.ErrP)
+			m.Actions = append(m.Actions,
+				notify.Action{Key: "file", Label: i.msg("Open file"), Handler: func() { i.openPath(file) }},
+				notify.Action{Key: "show", Label: i.msg("Show in folder"), Handler: func() { i.showInFolder(file) }})
+		}
+	case hooks.NewItems:
+		m.Body = i.msg("New files synchronized:") + "\n" + strings.Join(n.items[:min(len(n.items), 5)], "\n")
+		m.Category, m.Icon = "transfer.complete", i.icon.StatusIcon("idle")
+		file := filepath.Join(yd.Path, n.items[0])
+		m.Actions = []notify.Action{{Key: "file", Label: i.msg("Open file"), Handler: func() { i.openPath(file) }}}
+	case tools.QuotaWarning:
+		m.Body = i.msg("Low disk space: used %s of %s", yds.Used, yds.Total)
+		m.Urgency, m.Timeout, m.Transient = notify.UrgencyNormal, 0, false
+		m.Actions = []notify.Action{{Key: "site", Label: i.msg("Open Yandex.Disk in browser"), Handler: func() { i.openPath(ydURL) }}}
+	default:
+		return
+	}
+	i.notifySend(m)
+}
+
+// showOutput sends the notification with the daemon output
+func (i *indicator) showOutput(yd *ydisk.YDisk) {
+	i.notifySend(notify.Message{Title: i.msg("Yandex.Disk daemon output"), Body: yd.Output(), Urgency: notify.UrgencyNormal})
 }
@@ -0,0 +1,63 @@
+package ydisk
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// excludeOption is the daemon configuration file option with the folders excluded from synchronization
+const excludeOption = "exclude-dirs="
+
+// Exclude adds the folder to the folders that are excluded from synchronization (exclude-dirs option of daemon
+// configuration file). The folder path is relative to the synchronized folder. The running daemon is restarted
+// to apply the changed configuration.
+func (yd *YDisk) Exclude(dir string) error {
+	if err := excludeDir(yd.conf, dir); err != nil {
+		return err
+	}
+	yd.log.Info("daemon_exclude", "dir", dir)
+	if yd.getOutput(true) == "" {
+		return nil
+	}
+	if err := yd.Stop(); err != nil {
+		return err
+	}
+	return yd.Start()
+}
+
+// excludeDir adds the folder to exclude-dirs option of daemon configuration file. The option is added when
+// the file has no such option. The file is not changed when the folder is already excluded.
+func excludeDir(conf, dir string) error {
+	dir = strings.Trim(dir, "/")
+	if dir == "" || strings.ContainsAny(dir, `,"`+"\n") {
+		return fmt.Errorf("wrong folder name for exclusion: '%s'", dir)
+	}
+	info, err := os.Stat(conf)
+	if err != nil {
+		return fmt.Errorf("daemon configuration file error: %w", err)
+	}
+	data, err := os.ReadFile(conf)
+	if err != nil {
+		return fmt.Errorf("daemon configuration file reading error: %w", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	n := slices.IndexFunc(lines, func(line string) bool { return strings.HasPrefix(line, excludeOption) })
+	if n < 0 {
+		lines = append(lines, excludeOption+`""`)
+		n = len(lines) - 1
+	}
+	var dirs []string
+	if value := strings.Trim(strings.TrimPrefix(lines[n], excludeOption), `"`); value != "" {
+		dirs = strings.Split(value, ",")
+	}
+	if slices.Contains(dirs, dir) {
+		return nil
+	}
+	lines[n] = fmt.Sprintf(`%s"%s"`, excludeOption, strings.Join(append(dirs, dir), ","))
+	if err := os.WriteFile(conf, []byte(strings.Join(lines, "\n")+"\n"), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("daemon configuration file writing error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package ydisk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludeDir(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "config.cfg")
+	require.NoError(t, os.WriteFile(conf, []byte("dir=\"/home/user/Yandex.Disk\"\nauth=\"/home/user/passwd\"\n"), 0600))
+	read := func() string {
+		data, err := os.ReadFile(conf)
+		require.NoError(t, err)
+		return string(data)
+	}
+	require.NoError(t, excludeDir(conf, "photos/"))
+	require.Equal(t, "dir=\"/home/user/Yandex.Disk\"\nauth=\"/home/user/passwd\"\nexclude-dirs=\"photos\"\n", read())
+	require.NoError(t, excludeDir(conf, "docs/old"))
+	require.NoError(t, excludeDir(conf, "photos"))
+	require.Equal(t, "dir=\"/home/user/Yandex.Disk\"\nauth=\"/home/user/passwd\"\nexclude-dirs=\"photos,docs/old\"\n", read())
+	require.Error(t, excludeDir(conf, "a,b"))
+	require.Error(t, excludeDir(conf, "/"))
+	require.Error(t, excludeDir(filepath.Join(t.TempDir(), "none.cfg"), "photos"))
+	info, err := os.Stat(conf)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,55 @@
+package ydisk
+
+import (
+	"slices"
+	"strings"
+)
+
+// Status is the daemon status as the indicator displays it
+type Status string
+
+// Statuses of daemon
+const (
+	StatusUnknown Status = "unknown" // the status is not received yet or the daemon reported unrecognized status
+	StatusStopped Status = "none"    // the daemon is not started
+	StatusIdle    Status = "idle"    // all files are synchronized
+	StatusBusy    Status = "busy"    // synchronization or indexing is in progress
+	StatusPaused  Status = "paused"  // synchronization is paused
+	StatusError   Status = "error"   // synchronization error
+	StatusOffline Status = "offline" // there is no internet access
+	StatusAuth    Status = "auth"    // the daemon requires authorization
+)
+
+// daemonStatuses maps all known daemon statuses to the indicator statuses
+var daemonStatuses = map[string]Status{
+	"unknown":            StatusUnknown,
+	"none":               StatusStopped,
+	"idle":               StatusIdle,
+	"busy":               StatusBusy,
+	"index":              StatusBusy,
+	"paused":             StatusPaused,
+	"error":              StatusError,
+	"no internet access": StatusOffline,
+}
+
+// ParseStatus converts the daemon status and error message into the indicator status. The error that requires
+// authorization is reported as StatusAuth. It returns StatusUnknown and false for unrecognized daemon status.
+func ParseStatus(stat, err string) (Status, bool) {
+	status, ok := daemonStatuses[stat]
+	if !ok {
+		return StatusUnknown, false
+	}
+	if status == StatusError && IsAuthError(err) {
+		return StatusAuth, true
+	}
+	return status, true
+}
+
+// authErrors are the daemon error messages that require the user authorization
+var authErrors = []string{"not authorized", "authorization error", "authorization failed"}
+
+// IsAuthError returns true for the daemon error that requires the user authorization
+func IsAuthError(err string) bool {
+	err = strings.ToLower(err)
+	return slices.ContainsFunc(authErrors, func(e string) bool { return strings.Contains(err, e) })
+}
@@ -0,0 +1,42 @@
+package ydisk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		stat, err string
+		status    Status
+		known     bool
+	}{
+		{"idle", "", StatusIdle, true},
+		{"index", "", StatusBusy, true},
+		{"busy", "", StatusBusy, true},
+		{"paused", "", StatusPaused, true},
+		{"none", "", StatusStopped, true},
+		{"unknown", "", StatusUnknown, true},
+		{"no internet access", "", StatusOffline, true},
+		{"error", "access error", StatusError, true},
+		{"error", "Authorization error", StatusAuth, true},
+		{"rebooting", "", StatusUnknown, false},
+	}
+	for _, tc := range tests {
+		status, known := ParseStatus(tc.stat, tc.err)
+		require.Equal(t, tc.status, status, tc.stat)
+		require.Equal(t, tc.known, known, tc.stat)
+	}
+}
+
+func TestIsAuthError(t *testing.T) {
+	require.True(t, IsAuthError("Authorization error"))
+	require.True(t, IsAuthError("not authorized"))
+	require.True(t, IsAuthError("Error: authorization failed"))
+	require.False(t, IsAuthError("access error"))
+	require.False(t, IsAuthError(""))
+	require.False(t, IsAuthError("access error: /home/user/Yandex.Disk/author.txt"))
+	require.False(t, IsAuthError("access error: /home/user/Yandex.Disk/authorization.pdf"))
+	require.False(t, IsAuthError("oauth proxy is not available"))
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
@@ -17,8 +18,6 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
-var log *slog.Logger
-
 // YDvals - Daemon Status structure with fields that are updated on each change in the daemon status.
 // It is used for sending changes through YDisk.Changes channel.
 type YDvals struct {
@@ -149,12 +148,68 @@ func (val *YDvals) update(out string) bool {
 	return changed || val.ChLast
 }
 
+// Progress returns the synchronization progress in percents or -1 when the progress is not available.
+// The progress value looks like "65.34 MB/ 139.38 MB (46 %)".
+func (val *YDvals) Progress() int {
+	o := strings.LastIndex(val.Prog, "(")
+	c := strings.LastIndex(val.Prog, "%")
+	if o < 0 || c < o {
+		return -1
+	}
+	p, err := strconv.Atoi(strings.TrimSpace(val.Prog[o+1 : c]))
+	if err != nil {
+		return -1
+	}
+	return p
+}
+
+// UsedPercent returns the used space in percents of total space or -1 when the sizes are not available
+func (val *YDvals) UsedPercent() int {
+	used, ok := ParseSize(val.Used)
+	if !ok {
+		return -1
+	}
+	total, ok := ParseSize(val.Total)
+	if !ok || total == 0 {
+		return -1
+	}
+	return int(used * 100 / total)
+}
+
+// sizeUnits are the multipliers for the size units used in daemon output
+var sizeUnits = map[string]float64{
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+// ParseSize converts the size value from daemon output (like "2.89 GB") into the number of bytes.
+// It returns false when the value can't be parsed.
+func ParseSize(s string) (int64, bool) {
+	num, unit, found := strings.Cut(strings.TrimSpace(s), " ")
+	if !found {
+		return 0, false
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int64(v * mult), true
+}
+
 type watcher struct {
 	*fsnotify.Watcher
-	active bool // Flag that means that watching path was successfully added
+	active bool         // Flag that means that watching path was successfully added
+	log    *slog.Logger // logger of YDisk
 }
 
-func newWatcher() watcher {
+func newWatcher(log *slog.Logger) watcher {
 	watch, err := fsnotify.NewWatcher()
 	if err != nil {
 		log.Error("file_watcher", "error", err)
@@ -163,6 +218,7 @@ func newWatcher() watcher {
 	return watcher{
 		Watcher: watch,
 		active:  false,
+		log:     log,
 	}
 }
 
@@ -171,10 +227,10 @@ func (w *watcher) activate(path string) {
 		path := filepath.Join(path, ".sync/cli.log")
 		err := w.Add(path)
 		if err != nil {
-			log.Debug("file_watcher", "path", path, "error", err)
+			w.log.Debug("file_watcher", "path", path, "error", err)
 			return
 		}
-		log.Debug("file_watcher", "status", "added")
+		w.log.Debug("file_watcher", "status", "added")
 		w.active = true
 	}
 }
@@ -189,6 +245,7 @@ type YDisk struct {
 	exe      string        // Path to yandex-disk executable
 	exit     chan struct{} // Stop signal/replay channel for Event handler routine
 	activate func()        // Function to activate watcher after daemon creation
+	log      *slog.Logger  // logger of YDisk, it is not shared with other YDisk instances
 }
 
 // NewYDisk creates new YDisk structure for communication with yandex-disk daemon
@@ -201,13 +258,12 @@ type YDisk struct {
 //   - check that yandex-disk was properly configured
 //
 // When something not good NewYDisk returns not nil error
-func NewYDisk(conf string, logger *slog.Logger) (*YDisk, error) {
-	log = logger
+func NewYDisk(conf string, log *slog.Logger) (*YDisk, error) {
 	exe, path, err := checkDaemon(conf)
 	if err != nil {
 		return nil, err
 	}
-	watch := newWatcher()
+	watch := newWatcher(log)
 	log.Debug("yandex-disk", "executable", exe)
 	yd := YDisk{
 		Path:     path,
@@ -216,6 +272,7 @@ func NewYDisk(conf string, logger *slog.Logger) (*YDisk, error) {
 		exe:      exe,
 		exit:     make(chan struct{}),
 		activate: func() { watch.activate(path) },
+		log:      log,
 	}
 	// start event handler in separate goroutine
 	go yd.eventHandler(watch)
@@ -228,7 +285,7 @@ func NewYDisk(conf string, logger *slog.Logger) (*YDisk, error) {
 
 // eventHandler works in separate goroutine until YDisk.exit channel receives a struct{} value.
 func (yd *YDisk) eventHandler(watch watcher) {
-	log.Debug("daemon_event_handler", "status", "started")
+	yd.log.Debug("daemon_event_handler", "status", "started")
 	yds := newYDvals()
 	interval := 1
 	tick := time.NewTimer(time.Millisecond * 100) // First time trigger it quickly to update the current status
@@ -236,14 +293,14 @@ func (yd *YDisk) eventHandler(watch watcher) {
 		watch.Close()
 		tick.Stop()
 		close(yd.Changes)
-		log.Debug("daemon_event_handler", "status", "exited")
+		yd.log.Debug("daemon_event_handler", "status", "exited")
 		yd.exit <- struct{}{} // Report exit completion
 	}()
 	var source string
 	for {
 		select {
 		case err := <-watch.Errors:
-			log.Error("file_watcher", "error", err)
+			yd.log.Error("file_watcher", "error", err)
 			return
 		case <-yd.exit:
 			return
@@ -263,7 +320,7 @@ func (yd *YDisk) eventHandler(watch watcher) {
 		// in both cases (Timer or Watcher events):
 		//  - check for daemon changes and send changed values in case of change
 		if yds.update(yd.getOutput(false)) {
-			log.Debug("change", "source", source, "prev", yds.Prev, "new", yds.Stat,
+			yd.log.Debug("change", "source", source, "prev", yds.Prev, "new", yds.Stat,
 				"S", len(yds.Total) > 0, "L", len(yds.Last), "E", len(yds.Err) > 0)
 			yd.Changes <- yds
 			// in case of any change reset the timer interval
@@ -279,6 +336,20 @@ func (yd *YDisk) eventHandler(watch watcher) {
 	}
 }
 
+// Publish runs `yandex-disk publish` for the file or folder into synchronized folder. It returns the public link.
+func (yd *YDisk) Publish(path string) (string, error) {
+	out, err := exec.Command(yd.exe, "publish", "-c", yd.conf, path).Output()
+	if err != nil {
+		return "", fmt.Errorf("publishing error: %w", err)
+	}
+	link := strings.TrimSpace(string(out))
+	if link == "" {
+		return "", fmt.Errorf("publishing error: no public link")
+	}
+	yd.log.Debug("daemon_publish", "path", path, "link", link)
+	return link, nil
+}
+
 func (yd YDisk) getOutput(userLang bool) string {
 	cmd := []string{yd.exe, "status", "-c", yd.conf}
 	if !userLang {
@@ -288,7 +359,7 @@ func (yd YDisk) getOutput(userLang bool) string {
 	out, err := exec.Command(cmd[0], cmd[1:]...).Output()
 	if err != nil {
 		if message := strings.TrimSuffix(string(out), "\n"); message != "Error: daemon not started" {
-			log.Error("daemon_status", "error", err.Error(), "message", message)
+			yd.log.Error("daemon_status", "error", err.Error(), "message", message)
 		}
 		return ""
 	}
@@ -312,12 +383,12 @@ func (yd *YDisk) Start() error {
 	if yd.getOutput(true) == "" {
 		out, err := exec.Command(yd.exe, "start", "-c", yd.conf).Output()
 		if err != nil {
-			log.Error("daemon_start", "error", err)
+			yd.log.Error("daemon_start", "error", err)
 			return err
 		}
-		log.Debug("daemon_start", "message", string(bytes.TrimRight(out, " \n")))
+		yd.log.Debug("daemon_start", "message", string(bytes.TrimRight(out, " \n")))
 	} else {
-		log.Debug("daemon_start", "status", "already_started")
+		yd.log.Debug("daemon_start", "status", "already_started")
 	}
 	yd.activate() // try to activate watching after daemon start. It shouldn't fail on started daemon
 	return nil
@@ -328,12 +399,12 @@ func (yd *YDisk) Stop() error {
 	if yd.getOutput(true) != "" {
 		out, err := exec.Command(yd.exe, "stop", "-c", yd.conf).Output()
 		if err != nil {
-			log.Error("daemon stop", "error", err)
+			yd.log.Error("daemon stop", "error", err)
 			return err
 		}
-		log.Debug("daemon_stop", "message", string(bytes.TrimRight(out, " \n")))
+		yd.log.Debug("daemon_stop", "message", string(bytes.TrimRight(out, " \n")))
 	} else {
-		log.Debug("daemon_stop", "status", "already_stopped")
+		yd.log.Debug("daemon_stop", "status", "already_stopped")
 	}
 	return nil
 }
@@ -268,3 +268,39 @@ func TestFull(t *testing.T) {
 		}, time.Second, 100*time.Millisecond)
 	})
 }
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in  string
+		out int64
+		ok  bool
+	}{
+		{"0 B", 0, true},
+		{"512 B", 512, true},
+		{"1.5 KB", 1536, true},
+		{"2 MB", 2 << 20, true},
+		{"43.50 GB", int64(43.5 * (1 << 30)), true},
+		{"1 TB", 1 << 40, true},
+		{"", 0, false},
+		{"12", 0, false},
+		{"12 PB", 0, false},
+		{"x GB", 0, false},
+	}
+	for _, tc := range tests {
+		v, ok := ParseSize(tc.in)
+		require.Equal(t, tc.ok, ok, tc.in)
+		require.Equal(t, tc.out, v, tc.in)
+	}
+}
+
+func TestProgressAndUsed(t *testing.T) {
+	v := YDvals{Prog: "65.34 MB/ 139.38 MB (46 %)", Used: "10 GB", Total: "40 GB"}
+	require.Equal(t, 46, v.Progress())
+	require.Equal(t, 25, v.UsedPercent())
+	v = YDvals{Prog: "", Used: "", Total: "0 B"}
+	require.Equal(t, -1, v.Progress())
+	require.Equal(t, -1, v.UsedPercent())
+	v = YDvals{Prog: "65.34 MB/ 139.38 MB (x %)", Used: "1 GB", Total: "0 B"}
+	require.Equal(t, -1, v.Progress())
+	require.Equal(t, -1, v.UsedPercent())
+}